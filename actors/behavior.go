@@ -0,0 +1,299 @@
+package actors
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/kettek/ebihack23/commands"
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/res"
+)
+
+// Behavior is something a glitch does on the overworld. A glitch asks each of its behaviors in order whenever it thinks, and the first one that returns true decides what it does.
+type Behavior interface {
+	Think(g *Glitch, r *game.Room) bool
+}
+
+// BehaviorParams tune a behavior. Zero values use the behavior's own defaults.
+type BehaviorParams struct {
+	Range  int      `json:"range"`  // How many tiles away the player or allies are noticed from.
+	Weak   int      `json:"weak"`   // Percent of max integrity below which a glitch flees.
+	Points [][2]int `json:"points"` // Positions to patrol between. Rooms fill these in from an entity's patrol if left out.
+}
+
+// BehaviorDef names a behavior and how it is tuned, so species and rooms can describe behaviors without building them.
+type BehaviorDef struct {
+	Name string `json:"name"`
+	BehaviorParams
+}
+
+var behaviors = make(map[string]func(p BehaviorParams) Behavior)
+
+// NewBehavior creates the named behavior. Behaviors keep their own state, so every glitch needs its own.
+func NewBehavior(name string, p BehaviorParams) (Behavior, bool) {
+	b, ok := behaviors[name]
+	if !ok {
+		return nil, false
+	}
+	return b(p), true
+}
+
+// BehaviorNames returns the names of every registered behavior, sorted.
+func BehaviorNames() (names []string) {
+	for name := range behaviors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func orDefault(v, def int) int {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+func distance(x1, y1, x2, y2 int) int {
+	dx := x1 - x2
+	if dx < 0 {
+		dx = -dx
+	}
+	dy := y1 - y2
+	if dy < 0 {
+		dy = -dy
+	}
+	return dx + dy
+}
+
+// playerNear returns the player if it is within the given distance of x, y.
+func playerNear(r *game.Room, x, y, within int) game.Actor {
+	p := r.GetActorByTag("player")
+	if p == nil {
+		return nil
+	}
+	px, py, _ := p.Position()
+	if distance(x, y, px, py) > within {
+		return nil
+	}
+	return p
+}
+
+// stepTo steps towards x, y along a path, bumping into whatever is there once next to it.
+func (g *Glitch) stepTo(r *game.Room, x, y int) bool {
+	path := r.FindPath(g.X, g.Y, x, y)
+	if len(path) == 0 {
+		return false
+	}
+	if len(path) > 1 && r.GetActor(path[0][0], path[0][1]) != nil {
+		return false
+	}
+	g.step(path[0][0]-g.X, path[0][1]-g.Y)
+	return true
+}
+
+func (g *Glitch) step(x, y int) {
+	g.pendingCommands = append(g.pendingCommands, commands.Step{
+		X: x,
+		Y: y,
+	})
+}
+
+// Wander takes a random step.
+type Wander struct{}
+
+func (b *Wander) Think(g *Glitch, r *game.Room) bool {
+	x := rand.Intn(3) - 1
+	y := rand.Intn(3) - 1
+	if x != 0 && y != 0 {
+		if rand.Intn(2) == 0 {
+			x = 0
+		} else {
+			y = 0
+		}
+	}
+	g.step(x, y)
+	return true
+}
+
+// Chase goes after the player once it comes within range.
+type Chase struct {
+	Range int
+}
+
+func (b *Chase) Think(g *Glitch, r *game.Room) bool {
+	p := playerNear(r, g.X, g.Y, b.Range)
+	if p == nil {
+		return false
+	}
+	px, py, _ := p.Position()
+	return g.stepTo(r, px, py)
+}
+
+// Patrol walks between its points in a loop.
+type Patrol struct {
+	Points [][2]int
+	index  int
+}
+
+func (b *Patrol) Think(g *Glitch, r *game.Room) bool {
+	if len(b.Points) == 0 {
+		return false
+	}
+	p := b.Points[b.index]
+	if g.X == p[0] && g.Y == p[1] {
+		b.index = (b.index + 1) % len(b.Points)
+		p = b.Points[b.index]
+	}
+	g.stepTo(r, p[0], p[1])
+	// Even a blocked patrol is busy waiting its turn.
+	return true
+}
+
+// Guard chases the player only while it is within range of the glitch's home.
+type Guard struct {
+	Range int
+}
+
+func (b *Guard) Think(g *Glitch, r *game.Room) bool {
+	p := playerNear(r, g.HomeX, g.HomeY, b.Range)
+	if p == nil {
+		return false
+	}
+	px, py, _ := p.Position()
+	return g.stepTo(r, px, py)
+}
+
+// Flee runs from the player while the glitch's integrity is low.
+type Flee struct {
+	Range int
+	Weak  int
+}
+
+func (b *Flee) Think(g *Glitch, r *game.Room) bool {
+	_, _, integrity := g.CurrentStats()
+	_, _, maxIntegrity := g.MaxStats()
+	if maxIntegrity <= 0 || integrity*100/maxIntegrity >= b.Weak {
+		return false
+	}
+	p := playerNear(r, g.X, g.Y, b.Range)
+	if p == nil {
+		return false
+	}
+	px, py, _ := p.Position()
+	// Take whichever step gets us the farthest away.
+	best := [2]int{}
+	bestDistance := distance(g.X, g.Y, px, py)
+	for _, d := range [][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+		x, y := g.X+d[0], g.Y+d[1]
+		if !r.Walkable(x, y) || r.GetActor(x, y) != nil {
+			continue
+		}
+		if dist := distance(x, y, px, py); dist > bestDistance {
+			best = d
+			bestDistance = dist
+		}
+	}
+	if best == [2]int{} {
+		// Cornered.
+		return true
+	}
+	g.step(best[0], best[1])
+	return true
+}
+
+// Ambush hides in place until the player comes within range, then gives chase.
+type Ambush struct {
+	Range  int
+	sprung bool
+}
+
+func (b *Ambush) Think(g *Glitch, r *game.Room) bool {
+	p := playerNear(r, g.X, g.Y, b.Range)
+	if !b.sprung {
+		if p == nil {
+			g.hidden = true
+			return true
+		}
+		b.sprung = true
+		g.hidden = false
+		res.PlaySound("glitch")
+	}
+	if p == nil {
+		return false
+	}
+	px, py, _ := p.Position()
+	return g.stepTo(r, px, py)
+}
+
+// Swarm sticks close to other glitches and closes in on the player when it gets near any of them.
+type Swarm struct {
+	Range int
+}
+
+func (b *Swarm) Think(g *Glitch, r *game.Room) bool {
+	var ally game.Actor
+	allyDistance := 0
+	for _, a := range r.Actors {
+		if a == game.Actor(g) || !a.Glitch() {
+			continue
+		}
+		x, y, _ := a.Position()
+		if d := distance(g.X, g.Y, x, y); d <= b.Range && (ally == nil || d < allyDistance) {
+			ally = a
+			allyDistance = d
+		}
+	}
+	if ally == nil {
+		return false
+	}
+	ax, ay, _ := ally.Position()
+	if p := playerNear(r, ax, ay, b.Range); p != nil {
+		px, py, _ := p.Position()
+		return g.stepTo(r, px, py)
+	}
+	if allyDistance > 1 {
+		return g.stepTo(r, ax, ay)
+	}
+	return false
+}
+
+// Home walks back to where the glitch started once it has strayed farther than its range.
+type Home struct {
+	Range int
+}
+
+func (b *Home) Think(g *Glitch, r *game.Room) bool {
+	if distance(g.X, g.Y, g.HomeX, g.HomeY) <= b.Range {
+		return false
+	}
+	g.stepTo(r, g.HomeX, g.HomeY)
+	return true
+}
+
+func init() {
+	behaviors["wander"] = func(p BehaviorParams) Behavior {
+		return &Wander{}
+	}
+	behaviors["chase"] = func(p BehaviorParams) Behavior {
+		return &Chase{Range: orDefault(p.Range, 5)}
+	}
+	behaviors["patrol"] = func(p BehaviorParams) Behavior {
+		return &Patrol{Points: p.Points}
+	}
+	behaviors["guard"] = func(p BehaviorParams) Behavior {
+		return &Guard{Range: orDefault(p.Range, 3)}
+	}
+	behaviors["flee"] = func(p BehaviorParams) Behavior {
+		return &Flee{Range: orDefault(p.Range, 4), Weak: orDefault(p.Weak, 50)}
+	}
+	behaviors["ambush"] = func(p BehaviorParams) Behavior {
+		return &Ambush{Range: orDefault(p.Range, 2)}
+	}
+	behaviors["swarm"] = func(p BehaviorParams) Behavior {
+		return &Swarm{Range: orDefault(p.Range, 4)}
+	}
+	behaviors["home"] = func(p BehaviorParams) Behavior {
+		return &Home{Range: orDefault(p.Range, 3)}
+	}
+}
@@ -0,0 +1,83 @@
+package actors
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/kettek/ebihack23/commands"
+	"github.com/kettek/ebihack23/game"
+)
+
+// Block is a heavy crate that the player can push around, usually onto pressure plates.
+type Block struct {
+	Interactable
+	movingTicker int
+}
+
+func (b *Block) Pushable() bool {
+	return true
+}
+
+func (b *Block) Command(cmd commands.Command) {
+	switch cmd := cmd.(type) {
+	case commands.Step:
+		b.movingTicker = 10
+		b.targetX = b.X + cmd.X
+		b.targetY = b.Y + cmd.Y
+	}
+}
+
+func (b *Block) Update(room *game.Room) (cmd commands.Command) {
+	if b.movingTicker > 0 {
+		b.movingTicker--
+		if b.movingTicker == 0 {
+			b.X = b.targetX
+			b.Y = b.targetY
+		}
+	}
+	return nil
+}
+
+func (b *Block) Draw(screen *ebiten.Image, r *game.Room, geom ebiten.GeoM, drawMode game.DrawMode) {
+	var g ebiten.GeoM
+	var ratio float64
+	if b.movingTicker > 0 {
+		moveRatio := float64(b.movingTicker) / 10
+		g2, _ := r.GetTilePositionGeoM(b.X, b.Y)
+		g1, _ := r.GetTilePositionGeoM(b.targetX, b.targetY)
+		g.SetElement(0, 2, g1.Element(0, 2)*(1-moveRatio)+g2.Element(0, 2)*(moveRatio))
+		g.SetElement(1, 2, g1.Element(1, 2)*(1-moveRatio)+g2.Element(1, 2)*(moveRatio))
+	} else {
+		g, ratio = r.GetTilePositionGeoM(b.X, b.Y)
+	}
+	g.Concat(geom)
+	b.spriteStack.Draw(screen, g, drawMode, ratio)
+}
+
+func (b *Block) SetPosition(x, y, z int) {
+	b.X = x
+	b.Y = y
+	b.targetX = x
+	b.targetY = y
+}
+
+func init() {
+	actors["block"] = func(x, y int, ctor CreateFunc, interact InteractFunc) game.Actor {
+		ss := game.NewSpriteStack("block")
+		ss.LayerDistance = -1
+		b := &Block{
+			Interactable: Interactable{
+				X:           x,
+				Y:           y,
+				targetX:     x,
+				targetY:     y,
+				name:        "block",
+				spriteStack: ss,
+				onInteract:  interact,
+				blocks:      true,
+			},
+		}
+		if ctor != nil {
+			ctor(b)
+		}
+		return b
+	}
+}
@@ -18,6 +18,7 @@ type Combat struct {
 	penaltyPenetration int
 	penaltyFirewall    int
 	penaltyIntegrity   int
+	growth             [3]int // Percent of each base stat gained per level. 0 is 10%.
 	killed             bool
 	captured           bool
 	glitches           []game.GlitchActor
@@ -143,9 +144,9 @@ func (c *Combat) CurrentStats() (int, int, int) {
 func (c *Combat) MaxStats() (int, int, int) {
 	p, f, i := c.maxPenetration, c.maxFirewall, c.maxIntegrity
 
-	p += c.level * c.maxPenetration / 10
-	f += c.level * c.maxFirewall / 10
-	i += c.level * c.maxIntegrity / 10
+	p += c.level * c.maxPenetration * c.growthRate(0) / 100
+	f += c.level * c.maxFirewall * c.growthRate(1) / 100
+	i += c.level * c.maxIntegrity * c.growthRate(2) / 100
 
 	p -= c.penaltyPenetration
 	f -= c.penaltyFirewall
@@ -154,6 +155,18 @@ func (c *Combat) MaxStats() (int, int, int) {
 	return p, f, i
 }
 
+func (c *Combat) growthRate(stat int) int {
+	if c.growth[stat] == 0 {
+		return 10
+	}
+	return c.growth[stat]
+}
+
+// SetGrowth sets the percent of base penetration, firewall and integrity gained per level.
+func (c *Combat) SetGrowth(pen, fire, inte int) {
+	c.growth = [3]int{pen, fire, inte}
+}
+
 func (c *Combat) Level() int {
 	return c.level
 }
@@ -261,6 +274,8 @@ func (c *Combat) AddGlitch(g game.GlitchActor) {
 		gl.spriteStack.Highlight = false
 		gl.spriteStack.SkewX = 0
 		gl.spriteStack.SkewY = 0
+		gl.hidden = false
+		gl.alert = AlertIdle
 	}
 }
 
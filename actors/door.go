@@ -0,0 +1,137 @@
+package actors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kettek/ebihack23/commands"
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/res"
+)
+
+// Door takes whoever walks into it to a tagged actor in another room. It can be locked outright, needing a key item to open, and can also need a story flag to be set.
+type Door struct {
+	Interactable
+	Room             string // Room to travel to.
+	Target           string // Tag of the actor to arrive at.
+	OffsetX, OffsetY int    // Offset from the target to arrive at.
+	Locked           bool
+	Key              game.ItemType // Item that unlocks the door, used up when it does.
+	Flag             string        // Story flag that must be set for the door to open.
+	LockedSprite     string
+	UnlockedSprite   string
+	flagged          bool // If the flag was set as of the last update.
+	switched         bool // If SetSwitched has been called yet, so the starting state doesn't make a sound.
+}
+
+// IsLocked returns if the door can't currently be passed through.
+func (d *Door) IsLocked() bool {
+	return d.Locked || (d.Flag != "" && !d.flagged)
+}
+
+// SetLocked locks or unlocks the door, playing the lock sound if it changed.
+func (d *Door) SetLocked(locked bool) {
+	was := d.IsLocked()
+	d.Locked = locked
+	d.changed(was)
+}
+
+func (d *Door) Switches() []string {
+	if d.Flag == "" {
+		return nil
+	}
+	return []string{d.Flag}
+}
+
+func (d *Door) SetSwitched(on bool) {
+	was := d.IsLocked()
+	d.flagged = on
+	if !d.switched {
+		d.switched = true
+		d.refresh()
+		return
+	}
+	d.changed(was)
+}
+
+// changed plays the lock sound and swaps the sprite if the lock state is different from was.
+func (d *Door) changed(was bool) {
+	if was == d.IsLocked() {
+		return
+	}
+	if d.IsLocked() {
+		res.PlaySound("lock")
+	} else {
+		res.PlaySound("unlock")
+	}
+	d.refresh()
+}
+
+func (d *Door) refresh() {
+	sprite := d.UnlockedSprite
+	if d.IsLocked() {
+		sprite = d.LockedSprite
+	}
+	if sprite != "" && d.spriteStack.Sprite() != sprite {
+		d.spriteStack.SetSprite(sprite)
+	}
+}
+
+func (d *Door) Interact(w *game.World, r *game.Room, o game.Actor) commands.Command {
+	if d.onInteract != nil {
+		if cmd := d.onInteract(w, r, d, o); cmd != nil {
+			return cmd
+		}
+	}
+	if d.Locked && d.Key != game.ItemNone && o == w.PlayerActor {
+		if holder, ok := o.(game.ItemHolder); ok && holder.Inventory().Remove(d.Key) {
+			x, y, _ := o.Position()
+			r.TileMessage(game.Message{Text: fmt.Sprintf("used <%s>", d.Key), Duration: 2 * time.Second, Font: &res.SmallFont, X: x, Y: y})
+			d.SetLocked(false)
+		}
+	}
+	if d.IsLocked() {
+		if o == w.PlayerActor {
+			x, y, _ := o.Position()
+			msg := "it is locked"
+			if d.Locked && d.Key != game.ItemNone {
+				msg = fmt.Sprintf("it needs <%s>", d.Key)
+			}
+			r.TileMessage(game.Message{Text: msg, Duration: 2 * time.Second, Font: &res.SmallFont, X: x, Y: y})
+			res.PlaySound("lock")
+		}
+		return nil
+	}
+	if d.Room == "" {
+		return nil
+	}
+	return commands.Travel{
+		Room:    d.Room,
+		Tag:     d.Target,
+		OffsetX: d.OffsetX,
+		OffsetY: d.OffsetY,
+		Target:  o,
+	}
+}
+
+func init() {
+	actors["door"] = func(x, y int, ctor CreateFunc, interact InteractFunc) game.Actor {
+		ss := game.NewSpriteStack("haven-door-unlocked")
+		ss.LayerDistance = -1
+		d := &Door{
+			Interactable: Interactable{
+				X:           x,
+				Y:           y,
+				name:        "door",
+				spriteStack: ss,
+				onInteract:  interact,
+				blocks:      true,
+			},
+		}
+		if ctor != nil {
+			ctor(d)
+		}
+		d.refresh()
+		return d
+	}
+}
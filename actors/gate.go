@@ -0,0 +1,60 @@
+package actors
+
+import (
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/res"
+)
+
+// Gate is a barrier that opens while all of its flag conditions pass, such as when a pressure plate is held down or a switch is pulled.
+type Gate struct {
+	Interactable
+	Flags    []string
+	open     bool
+	switched bool // If SetSwitched has been called yet, so the starting state doesn't make a sound.
+}
+
+func (g *Gate) Switches() []string {
+	return g.Flags
+}
+
+func (g *Gate) SetSwitched(on bool) {
+	if g.switched && g.open == on {
+		return
+	}
+	if g.switched {
+		if on {
+			res.PlaySound("unlock")
+		} else {
+			res.PlaySound("lock")
+		}
+	}
+	g.switched = true
+	g.open = on
+	g.blocks = !on
+	if on {
+		g.spriteStack.SetSprite("gate-open")
+	} else {
+		g.spriteStack.SetSprite("gate")
+	}
+}
+
+func init() {
+	actors["gate"] = func(x, y int, ctor CreateFunc, interact InteractFunc) game.Actor {
+		ss := game.NewSpriteStack("gate")
+		ss.LayerDistance = -1
+		g := &Gate{
+			Interactable: Interactable{
+				X:           x,
+				Y:           y,
+				name:        "gate",
+				spriteStack: ss,
+				onInteract:  interact,
+				blocks:      true,
+			},
+		}
+		if ctor != nil {
+			ctor(g)
+		}
+		return g
+	}
+}
@@ -16,3 +16,9 @@ type CreateFunc = func(s game.Actor)
 type InteractFunc = func(w *game.World, r *game.Room, s, o game.Actor) commands.Command
 
 var actors = make(map[string]func(y, x int, ctor CreateFunc, interact InteractFunc) game.Actor)
+
+// Exists returns whether an actor type is registered.
+func Exists(actor string) bool {
+	_, ok := actors[actor]
+	return ok
+}
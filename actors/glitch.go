@@ -33,13 +33,26 @@ type Glitch struct {
 	pendingCommands  []commands.Command
 	warble           int
 	ready            bool
-	Target           game.Actor
+	Target           game.Actor // Chased before anything else, if set.
 	thinkTicker      int
+	ThinkRate        int        // Turns between thinking. 0 is 2.
+	Behaviors        []Behavior // Asked in order when thinking. If there are none, the glitch just wanders if it Wanders.
+	HomeX, HomeY     int
+	hidden           bool
+	Sight            int // How many tiles ahead the glitch can see the player from. 0 never notices it.
+	Patience         int // Turns the player can be out of sight before the glitch loses interest. 0 is 5.
+	alert            Alert
+	sees             bool
+	lastSeenX        int
+	lastSeenY        int
+	suspicion        int
+	outOfSight       int
 	Skews            bool
 	Floats           bool
 	Wanders          bool
 	ghosting         bool
 	ability          *game.Ability
+	species          string
 	warpEffects      []WarpEffect
 }
 
@@ -53,6 +66,7 @@ func (g *Glitch) SetReady(r bool) {
 
 func (g *Glitch) TakeTurn() (cmd commands.Command) {
 	g.thinkTicker--
+	g.updateAlert()
 	if len(g.pendingCommands) > 0 {
 		cmd = g.pendingCommands[0]
 		g.pendingCommands = g.pendingCommands[1:]
@@ -142,55 +156,65 @@ func (g *Glitch) Update(room *game.Room) (cmd commands.Command) {
 		return nil
 	}
 
-	// Do a little wandering if our brain is empty.
-	if g.thinkTicker <= 0 {
-		g.thinkTicker = 2
-		if g.Target != nil {
-			tx, ty, _ := g.Target.Position()
-			xdir := 0
-			if tx < g.X {
-				xdir = -1
-			} else if tx > g.X {
-				xdir = 1
-			}
-			ydir := 0
-			if ty < g.Y {
-				ydir = -1
-			} else if ty > g.Y {
-				ydir = 1
-			}
-			if xdir != 0 && ydir != 0 {
-				if rand.Intn(2) == 0 {
-					xdir = 0
-				} else {
-					ydir = 0
-				}
-			}
-			g.pendingCommands = append(g.pendingCommands, commands.Step{
-				X: xdir,
-				Y: ydir,
-			})
+	g.perceive(room)
+
+	if g.thinkTicker <= 0 && len(g.pendingCommands) == 0 {
+		g.thinkTicker = g.thinkRate()
+		g.think(room)
+	}
+
+	return nil
+}
+
+func (g *Glitch) thinkRate() int {
+	if g.ThinkRate <= 0 {
+		return 2
+	}
+	return g.ThinkRate
+}
 
-		} else if g.Wanders {
-			if len(g.pendingCommands) == 0 {
-				x := rand.Intn(3) - 1
-				y := rand.Intn(3) - 1
-				if x != 0 && y != 0 {
-					if rand.Intn(2) == 0 {
-						x = 0
-					} else {
-						y = 0
-					}
-				}
-				g.pendingCommands = append(g.pendingCommands, commands.Step{
-					X: x,
-					Y: y,
-				})
+func (g *Glitch) think(r *game.Room) {
+	if g.Target != nil {
+		tx, ty, _ := g.Target.Position()
+		xdir := 0
+		if tx < g.X {
+			xdir = -1
+		} else if tx > g.X {
+			xdir = 1
+		}
+		ydir := 0
+		if ty < g.Y {
+			ydir = -1
+		} else if ty > g.Y {
+			ydir = 1
+		}
+		if xdir != 0 && ydir != 0 {
+			if rand.Intn(2) == 0 {
+				xdir = 0
+			} else {
+				ydir = 0
 			}
 		}
+		g.step(xdir, ydir)
+		return
+	}
+	if g.thinkAlert(r) {
+		return
+	}
+	for _, b := range g.Behaviors {
+		if b.Think(g, r) {
+			return
+		}
+	}
+	// Do a little wandering if our brain is empty.
+	if len(g.Behaviors) == 0 && g.Wanders {
+		(&Wander{}).Think(g, r)
 	}
+}
 
-	return nil
+// Hidden returns true while the glitch is lying in wait.
+func (g *Glitch) Hidden() bool {
+	return g.hidden
 }
 
 func (g *Glitch) Input(in inputs.Input) bool {
@@ -198,6 +222,9 @@ func (g *Glitch) Input(in inputs.Input) bool {
 }
 
 func (g *Glitch) Draw(screen *ebiten.Image, r *game.Room, geom ebiten.GeoM, drawMode game.DrawMode) {
+	if g.hidden {
+		return
+	}
 	var gg ebiten.GeoM
 	var ratio float64
 	var offsetY float64
@@ -237,8 +264,12 @@ func (g *Glitch) Draw(screen *ebiten.Image, r *game.Room, geom ebiten.GeoM, draw
 	gg.Concat(geom)
 
 	g.spriteStack.Draw(screen, gg, drawMode, ratio)
+	g.drawAlert(screen, r, gg, ratio)
 }
 func (g *Glitch) DrawPost(screen, post *ebiten.Image, r *game.Room, geom ebiten.GeoM, drawMode game.DrawMode) {
+	if g.hidden {
+		return
+	}
 	// Get our position in the world.
 	tg, _ := r.GetTilePositionGeoM(g.X, g.Y)
 	for _, warp := range g.warpEffects {
@@ -336,6 +367,11 @@ func (g *Glitch) Glitch() bool {
 	return true
 }
 
+// SpeciesName returns the name of the species the glitch was made from, if any.
+func (g *Glitch) SpeciesName() string {
+	return g.species
+}
+
 func (g *Glitch) SetAbility(a *game.Ability) {
 	g.ability = a
 }
@@ -358,6 +394,8 @@ func init() {
 			shadow:      shadow,
 			onInteract:  interact,
 			Wanders:     true,
+			HomeX:       x,
+			HomeY:       y,
 		}
 		if ctor != nil {
 			ctor(p)
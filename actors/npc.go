@@ -0,0 +1,285 @@
+package actors
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/kettek/ebihack23/commands"
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/inputs"
+)
+
+// NPCLine is something an NPC says when it has no dialogue. The first line whose flag conditions all pass is used.
+type NPCLine struct {
+	If   []string
+	Text string
+}
+
+// NPC is another surviving SHOU unit. It walks its patrol, or follows someone, and talks when bumped into.
+type NPC struct {
+	name             string
+	tag              string
+	X, Y             int
+	movingTicker     int
+	targetX, targetY int
+	spriteStack      *game.SpriteStack
+	onInteract       InteractFunc
+	pendingCommands  []commands.Command
+	ready            bool
+	thinkTicker      int
+	Patrol           [][2]int // Positions walked to in a loop.
+	patrolIndex      int
+	Following        game.Actor
+	FollowsPlayer    bool   // Start following the player once it is in the room.
+	Dialogue         string // Name of a dialogue to run when talked to.
+	Lines            []NPCLine
+}
+
+func (n *NPC) Ready() bool {
+	return n.ready
+}
+
+func (n *NPC) SetReady(r bool) {
+	n.ready = r
+}
+
+func (n *NPC) TakeTurn() (cmd commands.Command) {
+	n.thinkTicker--
+	if len(n.pendingCommands) > 0 {
+		cmd = n.pendingCommands[0]
+		n.pendingCommands = n.pendingCommands[1:]
+	}
+	return cmd
+}
+
+func (n *NPC) Command(cmd commands.Command) {
+	switch cmd := cmd.(type) {
+	case commands.Face:
+		n.face(cmd.X, cmd.Y)
+	case commands.Step:
+		x := n.X + cmd.X
+		y := n.Y + cmd.Y
+		n.face(x, y)
+		n.movingTicker = 10
+		n.targetX = x
+		n.targetY = y
+	}
+}
+
+func (n *NPC) face(x, y int) {
+	if x < n.X {
+		n.spriteStack.Rotation = math.Pi * 3 / 2
+	} else if x > n.X {
+		n.spriteStack.Rotation = math.Pi / 2
+	} else if y < n.Y {
+		n.spriteStack.Rotation = 0
+	} else if y > n.Y {
+		n.spriteStack.Rotation = math.Pi
+	}
+}
+
+func (n *NPC) Update(room *game.Room) (cmd commands.Command) {
+	if n.movingTicker > 0 {
+		n.movingTicker--
+		if n.movingTicker == 0 {
+			n.X = n.targetX
+			n.Y = n.targetY
+		}
+		return nil
+	}
+
+	if n.thinkTicker > 0 || len(n.pendingCommands) > 0 {
+		return nil
+	}
+
+	if n.FollowsPlayer && n.Following == nil {
+		n.Following = room.GetActorByTag("player")
+	}
+
+	if n.Following != nil {
+		n.thinkTicker = 1
+		tx, ty, _ := n.Following.Position()
+		// Stay right behind whoever we're following.
+		if path := room.FindPath(n.X, n.Y, tx, ty); len(path) > 1 {
+			n.step(path[0])
+		}
+	} else if len(n.Patrol) > 0 {
+		n.thinkTicker = 2
+		p := n.Patrol[n.patrolIndex]
+		if n.X == p[0] && n.Y == p[1] {
+			n.patrolIndex = (n.patrolIndex + 1) % len(n.Patrol)
+			p = n.Patrol[n.patrolIndex]
+		}
+		if path := room.FindPath(n.X, n.Y, p[0], p[1]); len(path) > 0 && room.GetActor(path[0][0], path[0][1]) == nil {
+			n.step(path[0])
+		}
+	}
+
+	return nil
+}
+
+func (n *NPC) step(to [2]int) {
+	n.pendingCommands = append(n.pendingCommands, commands.Step{
+		X: to[0] - n.X,
+		Y: to[1] - n.Y,
+	})
+}
+
+func (n *NPC) Input(in inputs.Input) bool {
+	return false
+}
+
+func (n *NPC) Draw(screen *ebiten.Image, r *game.Room, geom ebiten.GeoM, drawMode game.DrawMode) {
+	var g ebiten.GeoM
+	var ratio float64
+	if n.movingTicker > 0 {
+		moveRatio := float64(n.movingTicker) / 10
+		g2, _ := r.GetTilePositionGeoM(n.X, n.Y)
+		g1, _ := r.GetTilePositionGeoM(n.targetX, n.targetY)
+		g.SetElement(0, 2, g1.Element(0, 2)*(1-moveRatio)+g2.Element(0, 2)*(moveRatio))
+		g.SetElement(1, 2, g1.Element(1, 2)*(1-moveRatio)+g2.Element(1, 2)*(moveRatio))
+	} else {
+		g, ratio = r.GetTilePositionGeoM(n.X, n.Y)
+	}
+	g.Concat(geom)
+	n.spriteStack.Draw(screen, g, drawMode, ratio)
+}
+
+func (n *NPC) DrawPost(screen, post *ebiten.Image, r *game.Room, geom ebiten.GeoM, drawMode game.DrawMode) {
+}
+
+func (n *NPC) SetPosition(x, y, z int) {
+	n.X = x
+	n.Y = y
+	n.targetX = x
+	n.targetY = y
+}
+
+func (n *NPC) Position() (int, int, int) {
+	return n.X, n.Y, 0
+}
+
+func (n *NPC) Hover(h bool) {
+	n.spriteStack.Highlight = h
+}
+
+func (n *NPC) Hovered() bool {
+	return n.spriteStack.Highlight
+}
+
+func (n *NPC) SetName(s string) {
+	n.name = s
+}
+
+func (n *NPC) Name() string {
+	return n.name
+}
+
+func (n *NPC) SetTag(s string) {
+	n.tag = s
+}
+
+func (n *NPC) Tag() string {
+	return n.tag
+}
+
+func (n *NPC) SpriteStack() *game.SpriteStack {
+	return n.spriteStack
+}
+
+// Interact turns to face whoever bumped into us and talks to them, using our dialogue if we have one and our lines otherwise.
+func (n *NPC) Interact(w *game.World, r *game.Room, o game.Actor) commands.Command {
+	if n.onInteract != nil {
+		if cmd := n.onInteract(w, r, n, o); cmd != nil {
+			return cmd
+		}
+	}
+	if o != w.PlayerActor {
+		return nil
+	}
+	ox, oy, _ := o.Position()
+	n.face(ox, oy)
+	if d := n.talk(w); d != nil {
+		return commands.Dialogue{Dialogue: d}
+	}
+	return nil
+}
+
+func (n *NPC) talk(w *game.World) *game.Dialogue {
+	if n.Dialogue != "" {
+		d, err := game.LoadDialogue(n.Dialogue)
+		if err != nil {
+			fmt.Println("couldn't talk:", err)
+			return nil
+		}
+		if d.Speaker == "" {
+			d.Speaker = n.name
+		}
+		if d.Portrait == "" {
+			d.Portrait = n.spriteStack.Sprite()
+		}
+		d.Handlers["follow"] = func(w *game.World) {
+			n.Following = w.PlayerActor
+		}
+		d.Handlers["stay"] = func(w *game.World) {
+			n.Following = nil
+			n.FollowsPlayer = false
+		}
+		return d
+	}
+	for _, l := range n.Lines {
+		if w.CheckFlags(l.If) {
+			return &game.Dialogue{
+				Start:    "line",
+				Speaker:  n.name,
+				Portrait: n.spriteStack.Sprite(),
+				Nodes: map[string]*game.DialogueNode{
+					"line": {Text: l.Text},
+				},
+				Handlers: make(map[string]func(w *game.World)),
+			}
+		}
+	}
+	return nil
+}
+
+func (n *NPC) Blocks() bool {
+	return true
+}
+
+func (n *NPC) SetBlocks(b bool) {
+}
+
+func (n *NPC) Ghosting() bool {
+	return false
+}
+
+func (n *NPC) SetGhosting(b bool) {
+}
+
+func (n *NPC) Glitch() bool {
+	return false
+}
+
+func init() {
+	actors["npc"] = func(x, y int, ctor CreateFunc, interact InteractFunc) game.Actor {
+		ss := game.NewSpriteStack("player")
+		ss.Shaded = true
+		ss.YScale = 1
+		ss.LayerDistance = -1
+		n := &NPC{
+			name:        "SHOU",
+			X:           x,
+			Y:           y,
+			targetX:     x,
+			targetY:     y,
+			spriteStack: ss,
+			onInteract:  interact,
+		}
+		if ctor != nil {
+			ctor(n)
+		}
+		return n
+	}
+}
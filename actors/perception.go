@@ -0,0 +1,184 @@
+package actors
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/kettek/ebihack23/commands"
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/res"
+)
+
+// Alert is how aware a glitch is of the player.
+type Alert int
+
+const (
+	AlertIdle       Alert = iota // Going about its behaviors.
+	AlertSuspicious              // Saw something and is going to look.
+	AlertHunting                 // Chasing the player down.
+)
+
+const (
+	defaultPatience   = 5 // Turns out of sight before a glitch gives up.
+	suspiciousTurns   = 2 // Turns the player must stay in sight before a suspicious glitch hunts.
+	noticeCloseRange  = 2 // The player is hunted right away when seen this close.
+	alertIconScale    = 0.5
+	alertIconFlatY    = -10.0
+	alertIconIsoY     = -24.0
+	alertIconHalfSize = 4.0
+)
+
+// Alert returns how aware the glitch is of the player.
+func (g *Glitch) Alert() Alert {
+	return g.alert
+}
+
+func (g *Glitch) setAlert(a Alert) {
+	if a == AlertHunting && g.alert != AlertHunting {
+		res.PlaySound("glitch")
+	}
+	g.alert = a
+	g.suspicion = 0
+	g.outOfSight = 0
+}
+
+func (g *Glitch) patience() int {
+	if g.Patience <= 0 {
+		return defaultPatience
+	}
+	return g.Patience
+}
+
+// facing returns the direction the glitch is looking, going by its rotation.
+func (g *Glitch) facing() (int, int) {
+	switch (int(math.Round(g.spriteStack.Rotation/(math.Pi/2)))%4 + 4) % 4 {
+	case 1:
+		return -1, 0
+	case 2:
+		return 0, -1
+	case 3:
+		return 1, 0
+	}
+	return 0, 1
+}
+
+// canSee returns true if the actor is within sight range, in front of the glitch and not behind a wall. Anything right next to it is always noticed.
+func (g *Glitch) canSee(r *game.Room, a game.Actor) bool {
+	ax, ay, _ := a.Position()
+	d := distance(g.X, g.Y, ax, ay)
+	if d > g.Sight {
+		return false
+	}
+	if d > 1 {
+		fx, fy := g.facing()
+		dx, dy := ax-g.X, ay-g.Y
+		forward := dx*fx + dy*fy
+		side := dx*fy - dy*fx
+		if side < 0 {
+			side = -side
+		}
+		if forward <= 0 || side > forward {
+			return false
+		}
+	}
+	return r.LineOfSight(g.X, g.Y, ax, ay)
+}
+
+// perceive looks for the player.
+func (g *Glitch) perceive(r *game.Room) {
+	g.sees = false
+	if g.Sight <= 0 || g.hidden || g.Target != nil {
+		return
+	}
+	p := r.GetActorByTag("player")
+	if p == nil || !g.canSee(r, p) {
+		return
+	}
+	g.sees = true
+	g.lastSeenX, g.lastSeenY, _ = p.Position()
+}
+
+// updateAlert moves the glitch between alert states. It is called once a turn.
+func (g *Glitch) updateAlert() {
+	if g.Sight <= 0 {
+		return
+	}
+	switch g.alert {
+	case AlertIdle:
+		if g.sees {
+			if distance(g.X, g.Y, g.lastSeenX, g.lastSeenY) <= noticeCloseRange {
+				g.setAlert(AlertHunting)
+			} else {
+				g.setAlert(AlertSuspicious)
+			}
+		}
+	case AlertSuspicious:
+		if g.sees {
+			g.outOfSight = 0
+			g.suspicion++
+			if g.suspicion >= suspiciousTurns || distance(g.X, g.Y, g.lastSeenX, g.lastSeenY) <= noticeCloseRange {
+				g.setAlert(AlertHunting)
+			}
+		} else {
+			g.outOfSight++
+			if g.outOfSight > g.patience() {
+				g.setAlert(AlertIdle)
+			}
+		}
+	case AlertHunting:
+		if g.sees {
+			g.outOfSight = 0
+		} else {
+			g.outOfSight++
+			if g.outOfSight > g.patience() {
+				// Go have a look where it was last seen before giving up entirely.
+				g.setAlert(AlertSuspicious)
+			}
+		}
+	}
+}
+
+// thinkAlert acts on the glitch's alert state, returning true if it did something.
+func (g *Glitch) thinkAlert(r *game.Room) bool {
+	switch g.alert {
+	case AlertHunting:
+		if p := r.GetActorByTag("player"); p != nil && g.sees {
+			px, py, _ := p.Position()
+			return g.stepTo(r, px, py)
+		}
+		return g.stepTo(r, g.lastSeenX, g.lastSeenY)
+	case AlertSuspicious:
+		if g.sees {
+			// Stop and stare.
+			g.Command(commands.Face{X: g.lastSeenX, Y: g.lastSeenY})
+			return true
+		}
+		if g.X != g.lastSeenX || g.Y != g.lastSeenY {
+			g.stepTo(r, g.lastSeenX, g.lastSeenY)
+		}
+		return true
+	}
+	return false
+}
+
+func (g *Glitch) drawAlert(screen *ebiten.Image, r *game.Room, geom ebiten.GeoM, ratio float64) {
+	if g.alert == AlertIdle {
+		return
+	}
+	flat := 0.0
+	switch r.DrawMode {
+	case game.DrawModeFlat:
+		flat = 1
+	case game.DrawModeFlatToIso, game.DrawModeIsoToFlat:
+		flat = ratio
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(alertIconScale, alertIconScale)
+	op.GeoM.Translate(res.TileHalfWidth*flat-alertIconHalfSize, alertIconFlatY*flat+alertIconIsoY*(1-flat))
+	op.GeoM.Concat(geom)
+	if g.alert == AlertSuspicious {
+		op.ColorScale.ScaleAlpha(0.5)
+	}
+	op.ColorScale.ScaleAlpha(g.spriteStack.Alpha)
+	screen.DrawImage(res.LoadImage("icon-exclamation"), op)
+}
@@ -0,0 +1,69 @@
+package actors
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/kettek/ebihack23/commands"
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/res"
+)
+
+// Pickup is an item lying in a room. It is picked up by walking onto it.
+type Pickup struct {
+	Interactable
+	Item  game.ItemType
+	Count int
+	spin  float64
+}
+
+func (p *Pickup) Update(room *game.Room) (cmd commands.Command) {
+	p.spin += 0.02
+	p.spriteStack.Rotation = p.spin
+	return nil
+}
+
+func (p *Pickup) Interact(w *game.World, r *game.Room, o game.Actor) commands.Command {
+	if p.onInteract != nil {
+		if cmd := p.onInteract(w, r, p, o); cmd != nil {
+			return cmd
+		}
+	}
+	holder, ok := o.(game.ItemHolder)
+	if !ok {
+		return nil
+	}
+	x, y, _ := o.Position()
+	added := holder.Inventory().Add(p.Item, p.Count)
+	if added == 0 {
+		r.TileMessage(game.Message{Text: fmt.Sprintf("can't carry more <%s>", p.Item), Duration: 2 * time.Second, Font: &res.SmallFont, X: x, Y: y})
+		return nil
+	}
+	r.RemoveActor(p)
+	r.TileMessage(game.Message{Text: fmt.Sprintf("got <%s> x%d", p.Item, added), Color: color.NRGBA{50, 200, 255, 255}, Duration: 2 * time.Second, Font: &res.SmallFont, X: x, Y: y})
+	res.PlaySound("slurp")
+	return nil
+}
+
+func init() {
+	actors["pickup"] = func(x, y int, ctor CreateFunc, interact InteractFunc) game.Actor {
+		ss := game.NewSpriteStack("item")
+		ss.LayerDistance = -1
+		p := &Pickup{
+			Interactable: Interactable{
+				X:           x,
+				Y:           y,
+				name:        "item",
+				spriteStack: ss,
+				onInteract:  interact,
+			},
+			Item:  game.ItemIntegrityPatch,
+			Count: 1,
+		}
+		if ctor != nil {
+			ctor(p)
+		}
+		return p
+	}
+}
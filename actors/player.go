@@ -8,12 +8,13 @@ import (
 	"github.com/kettek/ebihack23/game"
 	"github.com/kettek/ebihack23/inputs"
 	"github.com/kettek/ebihack23/res"
+	"github.com/kettek/ebihack23/settings"
 )
 
 type Player struct {
 	Combat
 	X, Y             int
-	prevX, prevY     int
+	trail            [][2]int // Tiles the player was on, most recent first. The party follows along these.
 	movingTicker     int
 	targetX, targetY int
 	spriteStack      *game.SpriteStack
@@ -22,12 +23,18 @@ type Player struct {
 	ready            bool
 	ghosting         bool
 	name             string
+	inventory        game.Inventory
 }
 
 func (p *Player) Ready() bool {
 	return p.ready && p.movingTicker == 0 && p.pendingCommand != nil
 }
 
+// Busy returns true while the player is stepping or has a turn waiting to be taken.
+func (p *Player) Busy() bool {
+	return p.movingTicker > 0 || p.pendingCommand != nil
+}
+
 func (p *Player) SetReady(r bool) {
 	p.ready = r
 }
@@ -85,8 +92,10 @@ func (p *Player) Update(room *game.Room) (cmd commands.Command) {
 	if p.movingTicker > 0 {
 		p.movingTicker--
 		if p.movingTicker == 0 {
-			p.prevX = p.X
-			p.prevY = p.Y
+			p.trail = append([][2]int{{p.X, p.Y}}, p.trail...)
+			if n := len(p.party()); len(p.trail) > n {
+				p.trail = p.trail[:n]
+			}
 			p.X = p.targetX
 			p.Y = p.targetY
 		}
@@ -148,15 +157,48 @@ func (p *Player) Draw(screen *ebiten.Image, r *game.Room, geom ebiten.GeoM, draw
 
 	p.spriteStack.Draw(screen, g, drawMode, ratio)
 
+	p.drawParty(screen, r, geom, drawMode)
+}
+
+// party returns the glitches that trail behind the player, the current glitch first.
+func (p *Player) party() (party []game.GlitchActor) {
 	if p.currentGlitch != nil {
+		party = append(party, p.currentGlitch)
+	}
+	for _, g := range p.glitches {
+		if g != p.currentGlitch {
+			party = append(party, g)
+		}
+	}
+	if settings.PartyTrail >= 0 && len(party) > settings.PartyTrail {
+		party = party[:settings.PartyTrail]
+	}
+	return party
+}
+
+// drawParty draws each glitch in the party on the trail, sliding along to the next spot while the player moves. Glitches without a spot on the trail yet, such as just after coming through a door, stay tucked away.
+func (p *Player) drawParty(screen *ebiten.Image, r *game.Room, geom ebiten.GeoM, drawMode game.DrawMode) {
+	party := p.party()
+	for i := len(party) - 1; i >= 0; i-- {
+		if i >= len(p.trail) {
+			continue
+		}
+		from := p.trail[i]
+		to := [2]int{p.X, p.Y}
+		if i > 0 {
+			to = p.trail[i-1]
+		}
+
+		var g ebiten.GeoM
+		var ratio float64
 		if p.movingTicker > 0 {
 			moveRatio := float64(p.movingTicker) / 10
-			g2, _ := r.GetTilePositionGeoM(p.prevX, p.prevY)
-			g1, _ := r.GetTilePositionGeoM(p.X, p.Y)
+			g2, _ := r.GetTilePositionGeoM(from[0], from[1])
+			g1, _ := r.GetTilePositionGeoM(to[0], to[1])
 			g.SetElement(0, 2, g1.Element(0, 2)*(1-moveRatio)+g2.Element(0, 2)*(moveRatio))
 			g.SetElement(1, 2, g1.Element(1, 2)*(1-moveRatio)+g2.Element(1, 2)*(moveRatio))
 		} else {
-			g, ratio = r.GetTilePositionGeoM(p.prevX, p.prevY)
+			g, ratio = r.GetTilePositionGeoM(from[0], from[1])
 		}
 		g.Concat(geom)
 
@@ -165,22 +207,24 @@ func (p *Player) Draw(screen *ebiten.Image, r *game.Room, geom ebiten.GeoM, draw
 		g.Reset()
 		g.Translate(x, y)
 
-		r := math.Atan2(float64(p.Y-p.prevY), float64(p.X-p.prevX))
-		//g.Translate(math.Cos(r)*res.TileWidth*2, math.Sin(r)*res.TileHeight*2)
-
-		p.currentGlitch.SpriteStack().Rotation = r - math.Pi/2
-
-		p.currentGlitch.SpriteStack().LayerDistance = -1
-		p.currentGlitch.SpriteStack().YScale = 0.5
-		p.currentGlitch.SpriteStack().Draw(screen, g, drawMode, ratio)
+		ss := party[i].SpriteStack()
+		if from != to {
+			ss.Rotation = math.Atan2(float64(to[1]-from[1]), float64(to[0]-from[0])) - math.Pi/2
+		}
+		ss.LayerDistance = -1
+		ss.YScale = 0.5
+		ss.Draw(screen, g, drawMode, ratio)
 	}
 }
+
 func (p *Player) DrawPost(screen, post *ebiten.Image, r *game.Room, geom ebiten.GeoM, drawMode game.DrawMode) {
 }
 
+// SetPosition puts the player somewhere new, such as through a door, so the party collapses back into it.
 func (p *Player) SetPosition(x, y, z int) {
 	p.X = x
 	p.Y = y
+	p.trail = nil
 }
 
 func (p *Player) Position() (int, int, int) {
@@ -252,6 +296,10 @@ func (p *Player) Glitch() bool {
 	return false
 }
 
+func (p *Player) Inventory() *game.Inventory {
+	return &p.inventory
+}
+
 func init() {
 	actors["player"] = func(x, y int, ctor CreateFunc, interact InteractFunc) game.Actor {
 		ss := game.NewSpriteStack("player")
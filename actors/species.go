@@ -0,0 +1,232 @@
+package actors
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/kettek/ebihack23/game"
+)
+
+// AbilityChance is an ability a species may be born with. Tier, Turns and Cooldown are inclusive [min, max] ranges.
+type AbilityChance struct {
+	Name     game.AbilityType
+	Weight   int
+	Tier     [2]int
+	Turns    [2]int
+	Cooldown [2]int
+}
+
+// Species describes a kind of glitch, so rooms only need to say which species and at what level.
+type Species struct {
+	Name      string
+	Sprite    string
+	Stats     [3]int // Base penetration, firewall and integrity.
+	Growth    [3]int // Percent of each base stat gained per level. 0 is 10%.
+	Floats    bool
+	Skews     bool
+	Wanders   bool
+	Z         int
+	YScale    float64 // 0 leaves the sprite's default.
+	Abilities []AbilityChance
+	NoAbility int // Weight of being born without any ability.
+	Behaviors []BehaviorDef
+	ThinkRate int
+	Sight     int // 0 never notices the player.
+	Patience  int
+}
+
+var species = make(map[string]*Species)
+
+// GetSpecies returns the named species.
+func GetSpecies(name string) (*Species, bool) {
+	s, ok := species[name]
+	return s, ok
+}
+
+// SpeciesNames returns the names of every registered species, sorted.
+func SpeciesNames() (names []string) {
+	for name := range species {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func rollRange(r [2]int) int {
+	if r[1] <= r[0] {
+		return r[0]
+	}
+	return r[0] + rand.Intn(r[1]-r[0]+1)
+}
+
+// RollAbility picks an ability from the species' pool by weight. Returns nil if none was picked.
+func (s *Species) RollAbility() *game.Ability {
+	total := s.NoAbility
+	for _, a := range s.Abilities {
+		total += a.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+	n := rand.Intn(total)
+	for _, a := range s.Abilities {
+		if n < a.Weight {
+			return &game.Ability{
+				Name:     string(a.Name),
+				Tier:     rollRange(a.Tier),
+				Turns:    rollRange(a.Turns),
+				Cooldown: rollRange(a.Cooldown),
+			}
+		}
+		n -= a.Weight
+	}
+	return nil
+}
+
+// Apply makes the glitch one of the species at the given level, with a freshly rolled ability.
+func (s *Species) Apply(g *Glitch, level int) error {
+	g.species = s.Name
+	g.SetName(s.Name)
+	g.spriteStack.SetSprite(s.Sprite)
+	if s.YScale != 0 {
+		g.spriteStack.YScale = s.YScale
+	}
+	g.Floats = s.Floats
+	g.Skews = s.Skews
+	g.Wanders = s.Wanders
+	g.Z = s.Z
+	g.ThinkRate = s.ThinkRate
+	g.Sight = s.Sight
+	g.Patience = s.Patience
+	g.Behaviors = nil
+	for _, def := range s.Behaviors {
+		b, ok := NewBehavior(def.Name, def.BehaviorParams)
+		if !ok {
+			return fmt.Errorf("species %s: missing behavior \"%s\"", s.Name, def.Name)
+		}
+		g.Behaviors = append(g.Behaviors, b)
+	}
+	g.SetGrowth(s.Growth[0], s.Growth[1], s.Growth[2])
+	g.SetLevel(level)
+	g.SetStats(s.Stats[0], s.Stats[1], s.Stats[2])
+	g.SetAbility(s.RollAbility())
+	return nil
+}
+
+func init() {
+	species["wanderer"] = &Species{
+		Name:    "wanderer",
+		Sprite:  "glitch-wanderer",
+		Stats:   [3]int{2, 8, 4},
+		Wanders: true,
+		Behaviors: []BehaviorDef{
+			{Name: "flee"},
+			{Name: "wander"},
+		},
+	}
+	species["slime"] = &Species{
+		Name:    "slime",
+		Sprite:  "glitch-slime",
+		Stats:   [3]int{4, 4, 8},
+		Skews:   true,
+		Wanders: true,
+		Behaviors: []BehaviorDef{
+			{Name: "swarm"},
+			{Name: "wander"},
+		},
+		ThinkRate: 3,
+		Sight:     3,
+	}
+	species["eye"] = &Species{
+		Name:    "eye",
+		Sprite:  "glitch-eye",
+		Stats:   [3]int{8, 4, 6},
+		Floats:  true,
+		Wanders: true,
+		Z:       1,
+		Behaviors: []BehaviorDef{
+			{Name: "guard", BehaviorParams: BehaviorParams{Range: 4}},
+			{Name: "home", BehaviorParams: BehaviorParams{Range: 2}},
+			{Name: "wander"},
+		},
+		Sight:    6,
+		Patience: 8,
+	}
+	species["warp"] = &Species{
+		Name:    "warp",
+		Sprite:  "glitch-warp",
+		Stats:   [3]int{10, 12, 10},
+		Skews:   true,
+		Wanders: true,
+		Behaviors: []BehaviorDef{
+			{Name: "flee", BehaviorParams: BehaviorParams{Weak: 30}},
+			{Name: "chase", BehaviorParams: BehaviorParams{Range: 4}},
+			{Name: "wander"},
+		},
+		ThinkRate: 1,
+		Sight:     4,
+		Abilities: []AbilityChance{
+			{Name: game.AbilityRandomDamage, Weight: 1, Tier: [2]int{2, 3}, Turns: [2]int{2, 4}, Cooldown: [2]int{1, 3}},
+		},
+	}
+	species["tripo"] = &Species{
+		Name:    "tripo",
+		Sprite:  "glitch-tripo",
+		Stats:   [3]int{16, 8, 10},
+		Floats:  true,
+		Wanders: true,
+		Z:       1,
+		Behaviors: []BehaviorDef{
+			{Name: "ambush", BehaviorParams: BehaviorParams{Range: 3}},
+			{Name: "home", BehaviorParams: BehaviorParams{Range: 5}},
+			{Name: "chase", BehaviorParams: BehaviorParams{Range: 6}},
+		},
+		Abilities: []AbilityChance{
+			{Name: game.AbilityCleave, Weight: 1, Tier: [2]int{1, 1}, Turns: [2]int{1, 1}, Cooldown: [2]int{2, 4}},
+		},
+	}
+	species["minpen"] = &Species{
+		Name:   "minpen",
+		Sprite: "minion-pen",
+		Stats:  [3]int{10, 5, 5},
+		Floats: true,
+		Z:      1,
+		YScale: 1,
+		Abilities: []AbilityChance{
+			{Name: game.AbilityPerfectHit, Weight: 1, Tier: [2]int{2, 2}, Turns: [2]int{2, 2}, Cooldown: [2]int{2, 2}},
+		},
+	}
+	species["minwall"] = &Species{
+		Name:   "minwall",
+		Sprite: "minion-wall",
+		Stats:  [3]int{5, 5, 10},
+		Floats: true,
+		Z:      1,
+		YScale: 1,
+		Abilities: []AbilityChance{
+			{Name: game.AbilityBlock, Weight: 1, Tier: [2]int{2, 2}, Turns: [2]int{4, 4}, Cooldown: [2]int{3, 3}},
+		},
+	}
+	species["minshel"] = &Species{
+		Name:   "minshel",
+		Sprite: "minion-shel",
+		Stats:  [3]int{5, 10, 5},
+		Floats: true,
+		Z:      1,
+		YScale: 1,
+		Abilities: []AbilityChance{
+			{Name: game.AbilityHardy, Weight: 1, Tier: [2]int{1, 1}, Turns: [2]int{3, 3}, Cooldown: [2]int{4, 4}},
+		},
+	}
+	species["SHOU-09"] = &Species{
+		Name:    "SHOU-09",
+		Sprite:  "badplayer",
+		Stats:   [3]int{10, 10, 10},
+		Skews:   true,
+		Wanders: true,
+		Abilities: []AbilityChance{
+			{Name: game.AbilityRandomDamage, Weight: 1, Tier: [2]int{10, 19}, Turns: [2]int{2, 4}, Cooldown: [2]int{1, 3}},
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package actors
+
+import (
+	"github.com/kettek/ebihack23/commands"
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/res"
+)
+
+// Switch is a lever that flips a story flag when the player bumps into it.
+type Switch struct {
+	Interactable
+	Flag string
+}
+
+func (s *Switch) Interact(w *game.World, r *game.Room, o game.Actor) commands.Command {
+	if s.onInteract != nil {
+		if cmd := s.onInteract(w, r, s, o); cmd != nil {
+			return cmd
+		}
+	}
+	if o != w.PlayerActor || s.Flag == "" {
+		return nil
+	}
+	w.SetFlag(s.Flag, !w.Flag(s.Flag))
+	res.PlaySound("button")
+	return nil
+}
+
+func (s *Switch) Switches() []string {
+	return []string{s.Flag}
+}
+
+func (s *Switch) SetSwitched(on bool) {
+	sprite := "switch"
+	if on {
+		sprite = "switch-on"
+	}
+	if s.spriteStack.Sprite() != sprite {
+		s.spriteStack.SetSprite(sprite)
+	}
+}
+
+func init() {
+	actors["switch"] = func(x, y int, ctor CreateFunc, interact InteractFunc) game.Actor {
+		ss := game.NewSpriteStack("switch")
+		ss.LayerDistance = -1
+		s := &Switch{
+			Interactable: Interactable{
+				X:           x,
+				Y:           y,
+				name:        "switch",
+				spriteStack: ss,
+				onInteract:  interact,
+				blocks:      true,
+			},
+		}
+		if ctor != nil {
+			ctor(s)
+		}
+		return s
+	}
+}
@@ -0,0 +1,224 @@
+// Package bot plays through the game by itself, without drawing anything, to check that it can still be beaten from start to end. It only gives the world the same inputs a player could, so its runs can also be recorded and replayed.
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kettek/ebihack23/actors"
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/replay"
+)
+
+// Goal is the room the bot heads for. The game is beaten by destroying the source within it.
+const Goal = "003_source"
+
+// endText is what the final message starts with.
+const endText = "THE END"
+
+// patience is how many decisions in a row the bot can make without getting anywhere before it gives up.
+const patience = 200
+
+// Report is how a run went.
+type Report struct {
+	Finished bool     // If "THE END" was shown.
+	Turns    int      // Turns the player took.
+	Ticks    int64    // Ticks simulated.
+	Rooms    []string // Rooms in the order they were first entered.
+	Fights   int
+	Captures int
+	Losses   int
+	Room     string // Room the bot ended in.
+	X, Y     int    // Where the bot ended.
+	Level    int
+	Stuck    string // Why the bot gave up, if it didn't finish.
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+	if r.Finished {
+		fmt.Fprintf(&b, "reached THE END in %d turns (%d ticks)\n", r.Turns, r.Ticks)
+	} else {
+		fmt.Fprintf(&b, "stuck in %s at %d,%d after %d turns (%d ticks): %s\n", r.Room, r.X, r.Y, r.Turns, r.Ticks, r.Stuck)
+	}
+	fmt.Fprintf(&b, "rooms: %s\n", strings.Join(r.Rooms, " -> "))
+	fmt.Fprintf(&b, "level %d, %d fights, %d captures, %d losses", r.Level, r.Fights, r.Captures, r.Losses)
+	return b.String()
+}
+
+// Bot drives a world towards the goal room.
+type Bot struct {
+	world    *game.World
+	report   Report
+	room     string
+	visited  map[string]bool
+	links    map[string]map[string]bool // Rooms each room has doors to.
+	used     map[game.Actor]bool        // Things already bumped into for what they do.
+	blocked  map[game.Actor]bool        // Doors that couldn't be opened or reached.
+	plan     []string                   // Combat menu items still to pick.
+	prompt   *game.Prompt
+	chosen   map[string]bool // Prompt items already picked, by message and item.
+	fighting bool
+	before   [3]int     // Party size, level and exp as the current fight started.
+	foe      game.Actor // Who the current fight is against.
+	idle     int        // Decisions in a row without getting anywhere.
+	lastX    int
+	lastY    int
+}
+
+// New makes a bot to drive the given world, which should have its player in place.
+func New(w *game.World) *Bot {
+	return &Bot{
+		world:   w,
+		visited: make(map[string]bool),
+		links:   make(map[string]map[string]bool),
+		used:    make(map[game.Actor]bool),
+		blocked: make(map[game.Actor]bool),
+		chosen:  make(map[string]bool),
+	}
+}
+
+// Play runs a bot through a fresh world with the given seed and starting room, for at most the given number of ticks.
+func Play(seed int64, room string, maxTicks int64) (Report, error) {
+	w, err := replay.NewWorld(seed, room)
+	if err != nil {
+		return Report{}, err
+	}
+	return New(w).Run(maxTicks), nil
+}
+
+// Run steps the world until the end is reached, the bot gets stuck, or it runs out of ticks.
+func (b *Bot) Run(maxTicks int64) Report {
+	for b.report.Ticks < maxTicks && !b.Done() {
+		b.Step()
+	}
+	if !b.Done() {
+		b.report.Stuck = fmt.Sprintf("ran out of ticks while %s", b.doing())
+	}
+	return b.Report()
+}
+
+// Done returns true once the end was reached or the bot gave up.
+func (b *Bot) Done() bool {
+	return b.report.Finished || b.report.Stuck != ""
+}
+
+// Report returns how the run has gone so far.
+func (b *Bot) Report() Report {
+	r := b.report
+	r.Rooms = append([]string{}, b.report.Rooms...)
+	if b.world.Room != nil {
+		r.Room = b.world.Room.ID
+	}
+	if b.world.PlayerActor != nil {
+		r.X, r.Y, _ = b.world.PlayerActor.Position()
+		r.Level = b.world.PlayerActor.(game.CombatActor).Level()
+	}
+	return r
+}
+
+// Step gives the world whatever input the bot wants this tick, then simulates it.
+func (b *Bot) Step() {
+	w := b.world
+	p, ok := w.PlayerActor.(*actors.Player)
+	if !ok {
+		b.report.Stuck = "there is no player"
+		return
+	}
+	b.act(p)
+	if w.Combat == nil && p.Ready() {
+		b.report.Turns++
+	}
+	w.Tick()
+	b.report.Ticks++
+	b.watch(p)
+}
+
+// watch keeps track of what happened during the last tick.
+func (b *Bot) watch(p *actors.Player) {
+	w := b.world
+	for _, m := range w.Messages {
+		if strings.HasPrefix(m.Text, endText) {
+			b.report.Finished = true
+		}
+	}
+	if w.Room != nil && w.Room.ID != b.room {
+		b.room = w.Room.ID
+		if !b.visited[b.room] {
+			b.visited[b.room] = true
+			b.report.Rooms = append(b.report.Rooms, b.room)
+		}
+		b.idle = 0
+	}
+	if w.Combat != nil && !b.fighting {
+		b.fighting = true
+		b.before = [3]int{len(p.Glitches()), p.Level(), p.Exp()}
+		b.foe, _ = w.Combat.Defender.(game.Actor)
+		b.report.Fights++
+	} else if w.Combat == nil && b.fighting {
+		b.fighting = false
+		b.plan = nil
+		b.idle = 0
+		// Winning always gives exp, fleeing leaves the foe where it was, and losing removes the foe along with some stats.
+		switch {
+		case len(p.Glitches()) > b.before[0]:
+			b.report.Captures++
+		case p.Level() > b.before[1] || p.Exp() != b.before[2]:
+		case b.foe != nil && b.inRoom(b.foe):
+		default:
+			b.report.Losses++
+		}
+	}
+}
+
+// inRoom returns true if the actor is still in the current room.
+func (b *Bot) inRoom(a game.Actor) bool {
+	for _, o := range b.world.Room.Actors {
+		if o == a {
+			return true
+		}
+	}
+	return false
+}
+
+// doing describes what the bot is currently up to, for reports.
+func (b *Bot) doing() string {
+	w := b.world
+	switch {
+	case len(w.Prompts) > 0:
+		return fmt.Sprintf("answering %q", w.Prompts[len(w.Prompts)-1].Message)
+	case w.Combat != nil:
+		return fmt.Sprintf("fighting %s", w.Combat.Defender.Name())
+	case w.Room != nil:
+		return fmt.Sprintf("exploring %s", w.Room.ID)
+	}
+	return "waiting"
+}
+
+// act picks this tick's input, if any.
+func (b *Bot) act(p *actors.Player) {
+	w := b.world
+	if len(w.Prompts) > 0 {
+		b.answer(w.Prompts[len(w.Prompts)-1])
+		return
+	}
+	b.prompt = nil
+	if w.Combat != nil {
+		if !w.Combat.Busy() {
+			b.fight(w.Combat, p)
+		}
+		return
+	}
+	if p.Busy() || w.Room == nil {
+		return
+	}
+	x, y, _ := p.Position()
+	if x != b.lastX || y != b.lastY {
+		b.lastX, b.lastY = x, y
+		b.idle = 0
+	} else if b.idle++; b.idle > patience {
+		b.report.Stuck = fmt.Sprintf("made no progress while %s", b.doing())
+		return
+	}
+	b.explore(p)
+}
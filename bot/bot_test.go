@@ -0,0 +1,24 @@
+//go:build headless
+
+package bot
+
+import (
+	"testing"
+
+	"github.com/kettek/ebihack23/game"
+)
+
+// TestFinishes plays the whole game through, so it's skipped with -short.
+func TestFinishes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("plays the whole game")
+	}
+	report, err := Play(1, "000_spawn", 60*60*game.TicksPerSecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Finished {
+		t.Fatalf("bot didn't finish:\n%s", report)
+	}
+	t.Logf("\n%s", report)
+}
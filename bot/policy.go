@@ -0,0 +1,300 @@
+package bot
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kettek/ebihack23/actors"
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/inputs"
+)
+
+// wanted are prompt items the bot picks first, such as those that lift a safeguard.
+var wanted = []string{"Unlock", "Manage Safeguard"}
+
+// leaving are prompt items that back out of a prompt.
+var leaving = []string{"Return", "Leave", "Goodbye", "Keep going", "BACK", "OK", "CANCEL", "..."}
+
+// captureChance is how likely a capture has to be before the bot tries one.
+const captureChance = 0.5
+
+// lowIntegrity is the share of integrity below which the bot patches itself up in a fight, and stops picking fights outside of one.
+const lowIntegrity = 0.4
+
+// fullParty is how many glitches the quarantine holds.
+const fullParty = 9
+
+// answer picks an item of the prompt: anything wanted that hasn't been picked yet, then a way out.
+func (b *Bot) answer(p *game.Prompt) {
+	if p != b.prompt {
+		b.prompt = p
+		b.chosen = make(map[string]bool)
+	}
+	pick := -1
+	for _, want := range wanted {
+		for i, item := range p.Items {
+			if item == want && !b.chosen[p.Message+"\n"+item] {
+				pick = i
+				break
+			}
+		}
+		if pick != -1 {
+			break
+		}
+	}
+	for _, leave := range leaving {
+		if pick != -1 {
+			break
+		}
+		for i, item := range p.Items {
+			if item == leave {
+				pick = i
+				break
+			}
+		}
+	}
+	if pick == -1 {
+		pick = len(p.Items) - 1
+	}
+	if pick < 0 {
+		b.world.Input(inputs.Cancel{})
+		return
+	}
+	b.chosen[p.Message+"\n"+p.Items[pick]] = true
+	for i := 0; p.Selected != pick && i < len(p.Items); i++ {
+		if p.Selected < pick {
+			b.world.Input(inputs.Direction{Y: 1})
+		} else {
+			b.world.Input(inputs.Direction{Y: -1})
+		}
+	}
+	b.world.Input(inputs.Confirm{})
+}
+
+// fight picks the next combat menu item, planning a new move once the last one is done.
+func (b *Bot) fight(c *game.Combat, p *actors.Player) {
+	if len(b.plan) == 0 {
+		b.plan = b.move(c, p)
+	}
+	items, selected := c.Menu()
+	pick := -1
+	for i, item := range items {
+		if strings.HasPrefix(item.Text, b.plan[0]) && !item.Disabled {
+			pick = i
+			break
+		}
+	}
+	if pick == -1 {
+		// Not in the menu we expected, so start over from the main menu.
+		b.plan = nil
+		b.world.Input(inputs.Cancel{})
+		return
+	}
+	b.plan = b.plan[1:]
+	for i := 0; selected != pick && i < len(items); i++ {
+		if selected < pick {
+			b.world.Input(inputs.Direction{Y: 1})
+		} else {
+			b.world.Input(inputs.Direction{Y: -1})
+		}
+		_, selected = c.Menu()
+	}
+	b.world.Input(inputs.Confirm{})
+}
+
+// move returns the menu items to pick for the next move. The bot patches itself up when low, captures when it is likely to work, and otherwise wears the foe down. Story glitches are destroyed rather than captured, as are any once the quarantine is full.
+func (b *Bot) move(c *game.Combat, p *actors.Player) []string {
+	_, _, inte := p.CurrentStats()
+	_, _, maxInte := p.MaxStats()
+	if float64(inte) < float64(maxInte)*lowIntegrity {
+		if p.Inventory().Count(game.ItemIntegrityPatch) > 0 {
+			return []string{"ITEM", string(game.ItemIntegrityPatch)}
+		}
+		return []string{"BOOST STAT", "INTEGRITY"}
+	}
+
+	destroy := len(p.Glitches()) >= fullParty
+	if a, ok := c.Defender.(game.Actor); ok && a.Tag() != "" {
+		destroy = true
+	}
+	if !destroy && c.CaptureChance() >= captureChance {
+		if p.Inventory().Count(game.ItemQuarantineCapsule) > 0 {
+			return []string{"ITEM", string(game.ItemQuarantineCapsule)}
+		}
+		return []string{"CAPTURE GLITCH"}
+	}
+
+	pen, fire, inte := c.Defender.CurrentStats()
+	switch {
+	case destroy || (pen <= 0 && fire <= 0):
+		return []string{"ATTACK", "INTEGRITY"}
+	case fire >= pen:
+		return []string{"ATTACK", "FIREWALL"}
+	}
+	return []string{"ATTACK", "PENETRATION"}
+}
+
+// explore takes the next step around the room: towards a glitch to fight, an item to pick up, or the door on the way to the goal.
+func (b *Bot) explore(p *actors.Player) {
+	r := b.world.Room
+	b.learn(r)
+
+	_, _, inte := p.CurrentStats()
+	_, _, maxInte := p.MaxStats()
+	healthy := float64(inte) >= float64(maxInte)*lowIntegrity
+	if healthy || r.ID == Goal {
+		if b.stepToNearest(p, func(a game.Actor) bool {
+			if h, ok := a.(interface{ Hidden() bool }); ok && h.Hidden() {
+				return false
+			}
+			return a.Glitch()
+		}) {
+			return
+		}
+	}
+	if b.stepToNearest(p, func(a game.Actor) bool {
+		_, ok := a.(*actors.Pickup)
+		return ok
+	}) {
+		return
+	}
+
+	door := b.nextDoor()
+	if door == nil {
+		b.report.Stuck = "found no way on towards " + Goal
+		return
+	}
+	if door.IsLocked() {
+		// Try whatever might open it, such as a terminal.
+		if b.stepToNearest(p, func(a game.Actor) bool {
+			switch a.(type) {
+			case *actors.Interactable, *actors.Switch:
+				return !b.used[a]
+			}
+			return false
+		}) {
+			return
+		}
+		b.blocked[door] = true
+		return
+	}
+	if !b.stepTo(p, door) {
+		// Something is in the way, so wait a while for it to move before trying another door.
+		if b.idle > patience/4 {
+			b.blocked[door] = true
+		}
+		b.wait()
+	}
+}
+
+// learn remembers where the room's doors lead.
+func (b *Bot) learn(r *game.Room) {
+	if b.links[r.ID] == nil {
+		b.links[r.ID] = make(map[string]bool)
+	}
+	for _, a := range r.Actors {
+		if d, ok := a.(*actors.Door); ok && d.Room != "" {
+			b.links[r.ID][d.Room] = true
+		}
+	}
+}
+
+// nextDoor returns the door to head for: one to the goal, else one to a room not yet visited, else the first of the way back to a room that has one.
+func (b *Bot) nextDoor() *actors.Door {
+	r := b.world.Room
+	var doors []*actors.Door
+	for _, a := range r.Actors {
+		if d, ok := a.(*actors.Door); ok && d.Room != "" && !b.blocked[d] {
+			doors = append(doors, d)
+		}
+	}
+	for _, d := range doors {
+		if d.Room == Goal {
+			return d
+		}
+	}
+	for _, d := range doors {
+		if !b.visited[d.Room] {
+			return d
+		}
+	}
+
+	// Search outwards through known rooms for one with a door to somewhere new.
+	first := make(map[string]string) // First room to go to on the way to each room.
+	open := []string{r.ID}
+	first[r.ID] = ""
+	for len(open) > 0 {
+		id := open[0]
+		open = open[1:]
+		// Go through them in order, so the same seed makes for the same run.
+		var links []string
+		for next := range b.links[id] {
+			links = append(links, next)
+		}
+		sort.Strings(links)
+		for _, next := range links {
+			if _, ok := first[next]; ok {
+				continue
+			}
+			if id == r.ID {
+				first[next] = next
+			} else {
+				first[next] = first[id]
+			}
+			if !b.visited[next] || next == Goal {
+				for _, d := range doors {
+					if d.Room == first[next] {
+						return d
+					}
+				}
+			}
+			open = append(open, next)
+		}
+	}
+	return nil
+}
+
+// stepToNearest steps towards the closest reachable actor that matches. Returns false if there was none.
+func (b *Bot) stepToNearest(p *actors.Player, match func(game.Actor) bool) bool {
+	r := b.world.Room
+	px, py, _ := p.Position()
+	var best [][2]int
+	var target game.Actor
+	for _, a := range r.Actors {
+		if a == game.Actor(p) || !match(a) {
+			continue
+		}
+		x, y, _ := a.Position()
+		path := r.FindPath(px, py, x, y)
+		if len(path) > 0 && (best == nil || len(path) < len(best)) {
+			best = path
+			target = a
+		}
+	}
+	if target == nil {
+		return false
+	}
+	if len(best) == 1 {
+		// About to bump into it.
+		b.used[target] = true
+	}
+	b.world.Input(inputs.Direction{X: best[0][0] - px, Y: best[0][1] - py})
+	return true
+}
+
+// stepTo steps towards the actor. Returns false if it can't be reached.
+func (b *Bot) stepTo(p *actors.Player, a game.Actor) bool {
+	px, py, _ := p.Position()
+	x, y, _ := a.Position()
+	path := b.world.Room.FindPath(px, py, x, y)
+	if len(path) == 0 {
+		return false
+	}
+	b.world.Input(inputs.Direction{X: path[0][0] - px, Y: path[0][1] - py})
+	return true
+}
+
+// wait passes a turn by looking at the tile in front.
+func (b *Bot) wait() {
+	b.world.Input(inputs.Direction{Y: 1, Mod: true})
+}
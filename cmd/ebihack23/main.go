@@ -1,7 +1,14 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"time"
+
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/kettek/ebihack23/inputs"
+	"github.com/kettek/ebihack23/replay"
+	"github.com/kettek/ebihack23/settings"
 	"github.com/kettek/ebihack23/states"
 )
 
@@ -9,6 +16,7 @@ type ebihack struct {
 }
 
 func (e *ebihack) Update() error {
+	inputs.Actions.Update()
 	return states.CurrentState.Update()
 }
 func (e *ebihack) Draw(screen *ebiten.Image) {
@@ -20,13 +28,41 @@ func (e *ebihack) Layout(outsideWidth, outsideHeight int) (int, int) {
 }
 
 func main() {
+	record := flag.String("record", "", "record the session's inputs to this file")
+	play := flag.String("replay", "", "play back a recorded session from this file")
+	flag.Parse()
+
+	if err := settings.Load(); err != nil {
+		fmt.Println("couldn't load settings:", err)
+	}
+
 	// Start dat gizame.
 	g := &ebihack{}
 
-	states.NextState(states.NewGame())
+	gs := states.NewGame()
+	if *play != "" {
+		rec, err := replay.Load(*play)
+		if err != nil {
+			panic(err)
+		}
+		gs.Playback = replay.NewPlayer(rec)
+	} else if *record != "" {
+		rec, err := replay.NewRecorder(time.Now().UnixNano(), states.StartRoom)
+		if err != nil {
+			panic(err)
+		}
+		gs.Recorder = rec
+	}
+	states.NextState(gs)
 	//ebiten.SetScreenFilterEnabled(false)
 
 	if err := ebiten.RunGame(g); err != nil {
 		panic(err)
 	}
+
+	if gs.Recorder != nil {
+		if err := gs.Recorder.Save(*record); err != nil {
+			fmt.Println("couldn't save recording:", err)
+		}
+	}
 }
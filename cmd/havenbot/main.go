@@ -0,0 +1,49 @@
+// havenbot plays through the game by itself and reports how far it got. Build it with `-tags headless` to run it without an audio device.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kettek/ebihack23/bot"
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/replay"
+	"github.com/kettek/ebihack23/states"
+)
+
+func main() {
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed for the run")
+	minutes := flag.Int("minutes", 60, "most minutes of game time to play for")
+	record := flag.String("record", "", "record the run's inputs to this file, for watching with -replay")
+	flag.Parse()
+
+	var w *game.World
+	var rec *replay.Recorder
+	var err error
+	if *record != "" {
+		if rec, err = replay.NewRecorder(*seed, states.StartRoom); err == nil {
+			w = rec.World()
+		}
+	} else {
+		w, err = replay.NewWorld(*seed, states.StartRoom)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	b := bot.New(w)
+
+	report := b.Run(int64(*minutes) * 60 * game.TicksPerSecond)
+	fmt.Printf("seed %d\n%s\n", *seed, report)
+
+	if rec != nil {
+		if err := rec.Save(*record); err != nil {
+			fmt.Println("couldn't save recording:", err)
+		}
+	}
+	if !report.Finished {
+		os.Exit(1)
+	}
+}
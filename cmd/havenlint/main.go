@@ -0,0 +1,57 @@
+// havenlint checks all of the game's rooms and dialogues for mistakes, exiting with a non-zero status if any are found. Warnings are printed but don't fail.
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/rooms"
+)
+
+func main() {
+	count := 0
+	report := func(s string) {
+		fmt.Fprintln(os.Stderr, s)
+		count++
+	}
+
+	for _, p := range rooms.Lint() {
+		if p.Warning {
+			fmt.Fprintln(os.Stderr, p.String())
+			continue
+		}
+		report(p.String())
+	}
+
+	if err := rooms.LoadError(); err != nil {
+		report(err.Error())
+	}
+
+	// Make sure every room actually builds, as scripts can still refer to things that don't exist.
+	names := rooms.GetRoomNames()
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := rooms.BuildRoom(name, game.NewClock()); err != nil {
+			report(fmt.Sprintf("failed to build: %v", err))
+		}
+	}
+
+	for _, name := range game.DialogueNames() {
+		d, err := game.LoadDialogue(name)
+		if err != nil {
+			report(err.Error())
+			continue
+		}
+		for _, p := range d.Lint() {
+			report(fmt.Sprintf("dialogue %s: %s", name, p))
+		}
+	}
+
+	if count > 0 {
+		fmt.Fprintf(os.Stderr, "%d problems\n", count)
+		os.Exit(1)
+	}
+	fmt.Println("ok")
+}
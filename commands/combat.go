@@ -6,9 +6,10 @@ type Combat struct {
 }
 
 type CombatResult struct {
-	Winner    interface{}
-	Loser     interface{}
-	ExpGained int
-	Destroyed bool
-	Fled      bool
+	Winner       interface{}
+	Loser        interface{}
+	ExpGained    int
+	CyclesGained int // Currency for vendor terminals.
+	Destroyed    bool
+	Fled         bool
 }
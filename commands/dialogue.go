@@ -0,0 +1,5 @@
+package commands
+
+type Dialogue struct {
+	Dialogue interface{}
+}
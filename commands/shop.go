@@ -0,0 +1,5 @@
+package commands
+
+type Shop struct {
+	Shop interface{}
+}
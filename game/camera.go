@@ -3,7 +3,8 @@ package game
 import (
 	"math"
 
-	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/kettek/ebihack23/inputs"
+	"github.com/kettek/ebihack23/settings"
 )
 
 type Camera struct {
@@ -84,16 +85,16 @@ func (c *Camera) Update() error {
 	if ebiten.IsKeyPressed(ebiten.KeyE) {
 		c.rotation += 0.05
 	}*/
-	if ebiten.IsKeyPressed(ebiten.KeyW) {
+	if inputs.Actions.Pressed(settings.ActionCameraUp) {
 		c.Y -= 1
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyS) {
+	if inputs.Actions.Pressed(settings.ActionCameraDown) {
 		c.Y += 1
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyA) {
+	if inputs.Actions.Pressed(settings.ActionCameraLeft) {
 		c.X -= 1
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyD) {
+	if inputs.Actions.Pressed(settings.ActionCameraRight) {
 		c.X += 1
 	}
 
@@ -0,0 +1,76 @@
+package game
+
+import "time"
+
+// Clock is the world's simulation clock. Anything timed, from messages to scripts, should run off of it rather than the wall clock, so that it can be paused, sped up, or stepped by hand.
+type Clock struct {
+	tick    int64
+	speed   int
+	paused  bool
+	pending int
+}
+
+func NewClock() *Clock {
+	return &Clock{
+		speed: 1,
+	}
+}
+
+// Now returns the current tick.
+func (c *Clock) Now() int64 {
+	return c.tick
+}
+
+// Since returns how many ticks have passed since the given tick.
+func (c *Clock) Since(tick int64) int64 {
+	return c.tick - tick
+}
+
+// Elapsed returns true if the given duration has passed since the given tick.
+func (c *Clock) Elapsed(tick int64, d time.Duration) bool {
+	return c.Since(tick) >= int64(DurationTicks(d))
+}
+
+func (c *Clock) Pause() {
+	c.paused = true
+}
+
+func (c *Clock) Resume() {
+	c.paused = false
+	c.pending = 0
+}
+
+func (c *Clock) Paused() bool {
+	return c.paused
+}
+
+// SetSpeed sets how many ticks are simulated per update. 1 is normal speed.
+func (c *Clock) SetSpeed(speed int) {
+	if speed < 1 {
+		speed = 1
+	}
+	c.speed = speed
+}
+
+func (c *Clock) Speed() int {
+	return c.speed
+}
+
+// Step queues up ticks to be simulated on the next update while the clock is paused.
+func (c *Clock) Step(ticks int) {
+	c.pending += ticks
+}
+
+// Ticks returns how many ticks should be simulated for this update.
+func (c *Clock) Ticks() int {
+	if c.paused {
+		ticks := c.pending
+		c.pending = 0
+		return ticks
+	}
+	return c.speed
+}
+
+func (c *Clock) advance() {
+	c.tick++
+}
@@ -26,7 +26,9 @@ type Combat struct {
 	turn          int
 	doneCommand   commands.Command
 	showGlitches  bool
-	image         *ebiten.Image
+	image         *ebiten.Image // Made on the first draw, so combat can be simulated without a screen.
+	width         int
+	height        int
 	isEnemyTurn   bool
 	menuMode      CombatMenuMode
 	action        CombatAction
@@ -41,6 +43,9 @@ type CombatLine struct {
 	color color.NRGBA
 }
 
+// reportDuck is how many updates music is turned down for after a line is added to the combat report.
+const reportDuck = 90
+
 var attackColor = color.NRGBA{255, 50, 50, 200}
 var defenseColor = color.NRGBA{50, 255, 50, 200}
 var neutralColor = color.NRGBA{200, 200, 200, 200}
@@ -190,10 +195,11 @@ func (c *CombatActionAttack) Update(cmb *Combat) {
 				c.next = &CombatActionDone{
 					isAttacker: c.isAttacker,
 					Result: commands.CombatResult{
-						Winner:    attacker,
-						Loser:     defender,
-						Destroyed: true,
-						ExpGained: defender.ExpValue(),
+						Winner:       attacker,
+						Loser:        defender,
+						Destroyed:    true,
+						ExpGained:    defender.ExpValue(),
+						CyclesGained: cyclesValue(defender),
 					},
 				}
 				c.timer = 120
@@ -435,6 +441,7 @@ type CombatActionCapture struct {
 	isAttacker bool
 	timer      int
 	caught     bool
+	bonus      float64 // Added to the capture chance, e.g. from a quarantine capsule.
 }
 
 func (c CombatActionCapture) IsAttacker() bool {
@@ -446,11 +453,12 @@ func (c CombatActionCapture) Done(cmb *Combat) (CombatAction, bool) {
 		return &CombatActionDone{
 			isAttacker: c.isAttacker,
 			Result: commands.CombatResult{
-				Winner:    cmb.Attacker,
-				Loser:     cmb.Defender,
-				ExpGained: cmb.Defender.ExpValue(),
-				Destroyed: false,
-				Fled:      false,
+				Winner:       cmb.Attacker,
+				Loser:        cmb.Defender,
+				ExpGained:    cmb.Defender.ExpValue(),
+				CyclesGained: cyclesValue(cmb.Defender),
+				Destroyed:    false,
+				Fled:         false,
 			},
 		}, true
 	}
@@ -463,11 +471,12 @@ func (c CombatActionCapture) Done(cmb *Combat) (CombatAction, bool) {
 		return &CombatActionDone{
 			isAttacker: c.isAttacker,
 			Result: commands.CombatResult{
-				Winner:    cmb.Attacker,
-				Loser:     cmb.Defender,
-				ExpGained: cmb.Defender.ExpValue(),
-				Destroyed: false,
-				Fled:      false,
+				Winner:       cmb.Attacker,
+				Loser:        cmb.Defender,
+				ExpGained:    cmb.Defender.ExpValue(),
+				CyclesGained: cyclesValue(cmb.Defender),
+				Destroyed:    false,
+				Fled:         false,
 			},
 		}, true
 	}
@@ -475,7 +484,12 @@ func (c CombatActionCapture) Done(cmb *Combat) (CombatAction, bool) {
 }
 
 func (c *CombatActionCapture) Try(cmb *Combat) bool {
-	return rand.Float64() < cmb.CaptureChance()
+	return rand.Float64() < cmb.CaptureChance()+c.bonus
+}
+
+// cyclesValue is how many cycles beating the actor is worth.
+func cyclesValue(a CombatActor) int {
+	return 1 + a.Level() + rand.Intn(3)
 }
 
 func (cmb *Combat) CaptureChance() float64 {
@@ -561,13 +575,42 @@ func (c CombatActionSwapGlitch) IsAttacker() bool {
 	return c.isAttacker
 }
 
+type CombatActionItem struct {
+	isAttacker bool
+	timer      int
+	item       ItemType
+}
+
+func (c CombatActionItem) Done(cmb *Combat) (CombatAction, bool) {
+	return nil, c.timer >= 120
+}
+
+func (c *CombatActionItem) Update(cmb *Combat) {
+	c.timer++
+	if c.timer == 10 {
+		cmb.AddReport(fmt.Sprintf("%s uses %s!", cmb.Attacker.Name(), c.item), res.LoadImage("icon-item"), neutralColor)
+	} else if c.timer == 60 {
+		if text, ok := UseItem(c.item, cmb.Attacker); ok {
+			cmb.AddReport(text, nil, defenseColor)
+			res.PlaySound("boost")
+		} else {
+			cmb.AddReport(text, nil, infoColor)
+			res.PlaySound("miss")
+		}
+	}
+}
+
+func (c CombatActionItem) IsAttacker() bool {
+	return c.isAttacker
+}
+
 type CombatMenu struct {
 	items         []CombatMenuItem
 	selectedIndex int
 }
 
 type CombatMenus struct {
-	main, attack, boost, swap, use CombatMenu
+	main, attack, boost, swap, use, items CombatMenu
 }
 
 type CombatMenuMode int
@@ -578,6 +621,7 @@ const (
 	CombatMenuModeBoostStat
 	CombatMenuModeUseGlitch
 	CombatMenuModeSwapGlitch
+	CombatMenuModeUseItem
 )
 
 type CombatMenuItem struct {
@@ -603,9 +647,21 @@ func (c *Combat) SwapMenu(mode CombatMenuMode) {
 		c.menu = &c.menus.swap
 	case CombatMenuModeUseGlitch:
 		c.menu = &c.menus.use
+	case CombatMenuModeUseItem:
+		c.menu = &c.menus.items
 	}
 }
 
+// Menu returns the items of the menu being shown and which one is selected.
+func (c *Combat) Menu() ([]CombatMenuItem, int) {
+	return c.menu.items, c.menu.selectedIndex
+}
+
+// Busy returns true while an action is playing out, during which the menu ignores input.
+func (c *Combat) Busy() bool {
+	return c.action != nil || c.doneCommand != nil
+}
+
 func (c *Combat) SetAction(action CombatAction) {
 	c.action = action
 }
@@ -616,6 +672,7 @@ func (c *Combat) AddReport(text string, icon *ebiten.Image, color color.NRGBA) {
 		text:  text,
 		color: color,
 	})
+	res.Mixer.Duck(reportDuck)
 
 	res.Text.Utils().StoreState()
 	res.Text.SetSize(float64(res.DefFont.Size))
@@ -700,13 +757,64 @@ func (c *Combat) RefreshGlitchSwap() {
 	c.menus.swap.selectedIndex = 0
 }
 
+func (c *Combat) RefreshItems() {
+	var itemMenuItems []CombatMenuItem
+	var inventory *Inventory
+	if holder, ok := c.Attacker.(ItemHolder); ok {
+		inventory = holder.Inventory()
+		for _, s := range inventory.Items {
+			if !ItemUsableInCombat(s.Type) {
+				continue
+			}
+			func(t ItemType) {
+				itemMenuItems = append(itemMenuItems, CombatMenuItem{
+					Text: fmt.Sprintf("%s x%d", t, s.Count),
+					Trigger: func() {
+						if t == ItemQuarantineCapsule && len(c.Attacker.Glitches()) >= 9 {
+							c.AddReport("the quarantine is full!", nil, neutralColor)
+							return
+						}
+						if !inventory.Remove(t) {
+							return
+						}
+						if t == ItemQuarantineCapsule {
+							c.AddReport(fmt.Sprintf("%s opens a %s!", c.Attacker.Name(), t), res.LoadImage("icon-item"), neutralColor)
+							c.SetAction(&CombatActionCapture{isAttacker: true, bonus: quarantineCapsuleBonus})
+							return
+						}
+						c.SetAction(&CombatActionItem{
+							isAttacker: true,
+							item:       t,
+						})
+					},
+				})
+			}(s.Type)
+		}
+	}
+	itemMenuItems = append(itemMenuItems, CombatMenuItem{
+		Text: "CANCEL",
+		Trigger: func() {
+			c.SwapMenu(CombatMenuModeMain)
+		},
+	})
+	c.menus.items.items = itemMenuItems
+	c.menus.items.selectedIndex = 0
+
+	for i, item := range c.menus.main.items {
+		if item.Text == "ITEM" {
+			c.menus.main.items[i].Disabled = inventory == nil || inventory.Empty()
+		}
+	}
+}
+
 func NewCombat(w, h int, attacker, defender CombatActor) *Combat {
 	var c *Combat
 
 	c = &Combat{
 		Attacker: attacker,
 		Defender: defender,
-		image:    ebiten.NewImage(w, h),
+		width:    w,
+		height:   h,
 		menus: CombatMenus{
 			main: CombatMenu{
 				items: []CombatMenuItem{
@@ -749,6 +857,13 @@ func NewCombat(w, h int, attacker, defender CombatActor) *Combat {
 						},
 						//Disabled: !attacker.HasGlitch(),
 					},
+					{
+						Icon: res.LoadImage("icon-item"),
+						Text: "ITEM",
+						Trigger: func() {
+							c.SwapMenu(CombatMenuModeUseItem)
+						},
+					},
 					{
 						Icon: res.LoadImage("icon-escape"),
 						Text: "FLEE",
@@ -842,19 +957,12 @@ func NewCombat(w, h int, attacker, defender CombatActor) *Combat {
 	}
 	c.RefreshGlitchUse()
 	c.RefreshGlitchSwap()
+	c.RefreshItems()
 	c.SwapMenu(CombatMenuModeMain)
-	c.Refresh()
 
 	return c
 }
 
-func (c *Combat) Refresh() {
-	c.image.Fill(color.NRGBA{66, 20, 66, 200})
-	pt := c.image.Bounds().Size()
-
-	vector.StrokeRect(c.image, 0, 0, float32(pt.X), float32(pt.Y), 4, color.NRGBA{245, 120, 245, 255}, true)
-}
-
 func (c *Combat) GenerateEnemyAction() CombatAction {
 	// FIXME: This is a placeholder. Enemy actions should be based on the enemy's stats as well as tendencies.
 	targets := []string{"INTEGRITY", "FIREWALL", "PENETRATION"}
@@ -912,6 +1020,7 @@ func (c *Combat) Update(w *World, r *Room) (cmd commands.Command) {
 					c.RefreshAbilities() // ... only the player can have abilities
 					c.RefreshGlitchSwap()
 					c.RefreshGlitchUse()
+					c.RefreshItems()
 				}
 			}
 		}
@@ -978,10 +1087,12 @@ func (c *Combat) Input(in inputs.Input) {
 		}
 		// TODO
 	}
-	c.Refresh()
 }
 
 func (c *Combat) Draw(screen *ebiten.Image, geom ebiten.GeoM) {
+	if c.image == nil {
+		c.image = ebiten.NewImage(c.width, c.height)
+	}
 	c.image.Clear()
 
 	// combat area width
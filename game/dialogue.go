@@ -0,0 +1,266 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kettek/ebihack23/res"
+)
+
+// Dialogue is a tree of nodes that is walked through the prompt system. Dialogues are loaded from res/dialogues/*.json.
+type Dialogue struct {
+	Start        string                    `json:"start"`
+	Speaker      string                    `json:"speaker"`  // Default speaker for nodes without one.
+	Portrait     string                    `json:"portrait"` // Default portrait sprite for nodes without one.
+	ShowVersions bool                      `json:"showVersions"`
+	Nodes        map[string]*DialogueNode  `json:"nodes"`
+	Handlers     map[string]func(w *World) `json:"-"` // Called by "call <name>" actions.
+	OnEnd        func(w *World)            `json:"-"`
+}
+
+// DialogueNode is a single screen of dialogue. If any of its branches pass, the dialogue is redirected to that branch's node instead.
+type DialogueNode struct {
+	Speaker  string           `json:"speaker"`
+	Portrait string           `json:"portrait"`
+	Text     string           `json:"text"`
+	Actions  []string         `json:"actions"` // Run when the node is shown.
+	Branches []DialogueBranch `json:"branches"`
+	Choices  []DialogueChoice `json:"choices"`
+	Next     string           `json:"next"` // Used when there are no choices.
+}
+
+type DialogueBranch struct {
+	If   []string `json:"if"`
+	Next string   `json:"next"`
+}
+
+// DialogueChoice is a selectable item. It is only shown if all of its conditions pass. An empty Next ends the dialogue.
+type DialogueChoice struct {
+	Text    string   `json:"text"`
+	If      []string `json:"if"`
+	Actions []string `json:"actions"`
+	Next    string   `json:"next"`
+}
+
+// LoadDialogue loads a fresh copy of the named dialogue.
+func LoadDialogue(name string) (*Dialogue, error) {
+	b, err := res.FS.ReadFile("dialogues/" + name + ".json")
+	if err != nil {
+		return nil, err
+	}
+	d := &Dialogue{
+		Handlers: make(map[string]func(w *World)),
+	}
+	if err := json.Unmarshal(b, d); err != nil {
+		return nil, fmt.Errorf("dialogue %s: %w", name, err)
+	}
+	if _, ok := d.Nodes[d.Start]; !ok {
+		return nil, fmt.Errorf("dialogue %s: missing start node \"%s\"", name, d.Start)
+	}
+	return d, nil
+}
+
+// RunAction runs a single dialogue action. Actions are "set <flag>", "unset <flag>", "sound <name>", and "call <handler>".
+func (d *Dialogue) RunAction(w *World, action string) {
+	op, arg, _ := strings.Cut(action, " ")
+	switch op {
+	case "set":
+		w.SetFlag(arg, true)
+	case "unset":
+		w.SetFlag(arg, false)
+	case "sound":
+		res.PlaySound(arg)
+	case "call":
+		if h, ok := d.Handlers[arg]; ok {
+			h(w)
+		} else {
+			fmt.Println("missing dialogue handler", arg)
+		}
+	default:
+		fmt.Println("unknown dialogue action", action)
+	}
+}
+
+// node returns the named node, following any passing branches. A node with nothing to show just routes on to its Next.
+func (d *Dialogue) node(w *World, name string) *DialogueNode {
+	// Limit redirects so a bad branch loop doesn't lock us up.
+	for i := 0; i < 16; i++ {
+		n, ok := d.Nodes[name]
+		if !ok {
+			fmt.Println("missing dialogue node", name)
+			return nil
+		}
+		redirected := false
+		for _, b := range n.Branches {
+			if w.CheckFlags(b.If) {
+				name = b.Next
+				redirected = true
+				break
+			}
+		}
+		if !redirected && n.Text == "" && len(n.Choices) == 0 && len(n.Actions) == 0 && n.Next != "" {
+			name = n.Next
+			redirected = true
+		}
+		if !redirected {
+			return n
+		}
+	}
+	return nil
+}
+
+type dialogueRunner struct {
+	dialogue *Dialogue
+	node     *DialogueNode
+	choices  []DialogueChoice
+	prompt   *Prompt
+	portrait string
+}
+
+// StartDialogue shows the dialogue's start node as a prompt.
+func (w *World) StartDialogue(d *Dialogue) {
+	r := &dialogueRunner{dialogue: d}
+	w.AddPrompt(nil, "", func(i int, s string) bool {
+		return r.choose(w, i)
+	}, d.ShowVersions)
+	r.prompt = w.Prompts[len(w.Prompts)-1]
+	if !r.show(w, d.Start) {
+		w.Prompts = w.Prompts[:len(w.Prompts)-1]
+		r.end(w)
+	}
+}
+
+func (r *dialogueRunner) show(w *World, name string) bool {
+	n := r.dialogue.node(w, name)
+	if n == nil {
+		return false
+	}
+	r.node = n
+	for _, a := range n.Actions {
+		r.dialogue.RunAction(w, a)
+	}
+
+	r.choices = r.choices[:0]
+	for _, c := range n.Choices {
+		if w.CheckFlags(c.If) {
+			r.choices = append(r.choices, c)
+		}
+	}
+	if len(n.Choices) == 0 {
+		r.choices = append(r.choices, DialogueChoice{Text: "...", Next: n.Next})
+	}
+	items := make([]string, len(r.choices))
+	for i, c := range r.choices {
+		items[i] = c.Text
+	}
+
+	speaker := n.Speaker
+	if speaker == "" {
+		speaker = r.dialogue.Speaker
+	}
+	portrait := n.Portrait
+	if portrait == "" {
+		portrait = r.dialogue.Portrait
+	}
+	if portrait != r.portrait {
+		r.portrait = portrait
+		r.prompt.Portrait = nil
+		if portrait != "" {
+			r.prompt.Portrait = NewSpriteStack(portrait)
+			r.prompt.Portrait.LayerDistance = -portraitScale
+		}
+	}
+
+	r.prompt.Speaker = speaker
+	r.prompt.Message = n.Text
+	r.prompt.SetItems(items)
+	r.prompt.Refresh()
+	return true
+}
+
+func (r *dialogueRunner) choose(w *World, i int) bool {
+	if i < 0 || i >= len(r.choices) {
+		r.end(w)
+		return true
+	}
+	c := r.choices[i]
+	for _, a := range c.Actions {
+		r.dialogue.RunAction(w, a)
+	}
+	if c.Next == "" || !r.show(w, c.Next) {
+		r.end(w)
+		return true
+	}
+	return false
+}
+
+func (r *dialogueRunner) end(w *World) {
+	if r.dialogue.OnEnd != nil {
+		r.dialogue.OnEnd(w)
+	}
+}
+
+// DialogueNames returns the names of all dialogues in res/dialogues.
+func DialogueNames() (names []string) {
+	entries, err := res.FS.ReadDir("dialogues")
+	if err != nil {
+		return nil
+	}
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".json"); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Lint returns problems with the dialogue's data, such as links to missing nodes or unknown sounds. Handlers can't be checked, as they are attached at runtime.
+func (d *Dialogue) Lint() (problems []string) {
+	checkNode := func(from, next string) {
+		if next == "" {
+			return
+		}
+		if _, ok := d.Nodes[next]; !ok {
+			problems = append(problems, fmt.Sprintf("node \"%s\" leads to missing node \"%s\"", from, next))
+		}
+	}
+	checkPortrait := func(from, portrait string) {
+		if portrait != "" && !res.HasSprite(portrait) {
+			problems = append(problems, fmt.Sprintf("node \"%s\" uses unknown portrait \"%s\"", from, portrait))
+		}
+	}
+	checkActions := func(from string, actions []string) {
+		for _, a := range actions {
+			op, arg, _ := strings.Cut(a, " ")
+			switch op {
+			case "set", "unset", "call":
+				if arg == "" {
+					problems = append(problems, fmt.Sprintf("node \"%s\" has action \"%s\" without an argument", from, a))
+				}
+			case "sound":
+				if !res.HasSound(arg) {
+					problems = append(problems, fmt.Sprintf("node \"%s\" plays unknown sound \"%s\"", from, arg))
+				}
+			default:
+				problems = append(problems, fmt.Sprintf("node \"%s\" has unknown action \"%s\"", from, a))
+			}
+		}
+	}
+
+	checkNode("start", d.Start)
+	checkPortrait("start", d.Portrait)
+	for name, n := range d.Nodes {
+		checkNode(name, n.Next)
+		checkPortrait(name, n.Portrait)
+		checkActions(name, n.Actions)
+		for _, b := range n.Branches {
+			checkNode(name, b.Next)
+		}
+		for _, c := range n.Choices {
+			checkNode(name, c.Next)
+			checkActions(name, c.Actions)
+		}
+	}
+	return problems
+}
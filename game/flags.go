@@ -0,0 +1,31 @@
+package game
+
+import "strings"
+
+// SetFlag sets or clears a story flag.
+func (w *World) SetFlag(name string, v bool) {
+	if v {
+		w.Flags[name] = true
+	} else {
+		delete(w.Flags, name)
+	}
+}
+
+// Flag returns whether the given story flag is set.
+func (w *World) Flag(name string) bool {
+	return w.Flags[name]
+}
+
+// CheckFlags returns true if all of the given conditions pass. A condition is a flag name, optionally prefixed with "!" to require it to be unset.
+func (w *World) CheckFlags(conditions []string) bool {
+	for _, c := range conditions {
+		if strings.HasPrefix(c, "!") {
+			if w.Flag(c[1:]) {
+				return false
+			}
+		} else if !w.Flag(c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package game
+
+import "sort"
+
+// GlitchdexEntry is what is known about a single glitch species. Glitches without a species are told apart by name.
+type GlitchdexEntry struct {
+	Species  string
+	Sprite   string
+	Seen     bool
+	Fought   bool
+	Captured bool
+	Stats    [3]int // Max penetration, firewall and integrity of the first one seen.
+	Level    int    // Level of the first one seen.
+	Ability  string
+	Rooms    []string // Room names it was found in, in the order they were found.
+	order    int
+}
+
+// Glitchdex records every glitch species the player has come across.
+type Glitchdex struct {
+	entries map[string]*GlitchdexEntry
+}
+
+func NewGlitchdex() *Glitchdex {
+	return &Glitchdex{
+		entries: make(map[string]*GlitchdexEntry),
+	}
+}
+
+// Entries returns every recorded species in the order they were first seen.
+func (g *Glitchdex) Entries() (entries []*GlitchdexEntry) {
+	for _, e := range g.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].order < entries[j].order
+	})
+	return entries
+}
+
+// Entry returns the entry for the species, if it has been seen.
+func (g *Glitchdex) Entry(species string) *GlitchdexEntry {
+	return g.entries[species]
+}
+
+// Count returns how many species have been seen and how many of those were captured.
+func (g *Glitchdex) Count() (seen, captured int) {
+	for _, e := range g.entries {
+		seen++
+		if e.Captured {
+			captured++
+		}
+	}
+	return
+}
+
+// WantsGlitchdex returns true once after the GLITCHDEX button was clicked.
+func (w *World) WantsGlitchdex() bool {
+	wants := w.wantsGlitchdex
+	w.wantsGlitchdex = false
+	return wants
+}
+
+// speciesOf returns the species name of the actor, falling back to its name.
+func speciesOf(a Actor) string {
+	if s, ok := a.(interface{ SpeciesName() string }); ok && s.SpeciesName() != "" {
+		return s.SpeciesName()
+	}
+	return a.Name()
+}
+
+// record returns the actor's entry, creating it from the actor if this is the first of its species.
+func (g *Glitchdex) record(a Actor, r *Room) *GlitchdexEntry {
+	e, ok := g.entries[speciesOf(a)]
+	if !ok {
+		e = &GlitchdexEntry{
+			Species: speciesOf(a),
+			Seen:    true,
+			order:   len(g.entries),
+		}
+		if ss := a.SpriteStack(); ss != nil {
+			e.Sprite = ss.Sprite()
+		}
+		if c, ok := a.(CombatActor); ok {
+			e.Stats[0], e.Stats[1], e.Stats[2] = c.MaxStats()
+			e.Level = c.Level()
+		}
+		if ga, ok := a.(GlitchActor); ok && ga.Ability() != nil {
+			e.Ability = ga.Ability().Name
+		}
+		g.entries[e.Species] = e
+	}
+	if r != nil && r.Name != "" {
+		found := false
+		for _, name := range e.Rooms {
+			if name == r.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			e.Rooms = append(e.Rooms, r.Name)
+		}
+	}
+	return e
+}
+
+// See records a glitch as seen in the given room.
+func (g *Glitchdex) See(a Actor, r *Room) {
+	g.record(a, r)
+}
+
+// Fight records a glitch as battled.
+func (g *Glitchdex) Fight(a Actor, r *Room) {
+	g.record(a, r).Fought = true
+}
+
+// Capture records a glitch as captured.
+func (g *Glitchdex) Capture(a Actor, r *Room) {
+	g.record(a, r).Captured = true
+}
@@ -0,0 +1,175 @@
+package game
+
+import "fmt"
+
+type ItemType string
+
+const (
+	ItemNone              ItemType = ""
+	ItemIntegrityPatch             = "INTEGRITY PATCH"
+	ItemFirewallRebuild            = "FIREWALL REBUILD"
+	ItemPenetrationScript          = "PENETRATION SCRIPT"
+	ItemQuarantineCapsule          = "QUARANTINE CAPSULE"
+	ItemAccessKey                  = "ACCESS KEY"
+)
+
+type ItemDescription string
+
+const (
+	ItemDescriptionNone              ItemDescription = ""
+	ItemDescriptionIntegrityPatch                    = "Restores up to 10 INTEGRITY."
+	ItemDescriptionFirewallRebuild                   = "Restores up to 10 FIREWALL."
+	ItemDescriptionPenetrationScript                 = "Restores up to 10 PENETRATION."
+	ItemDescriptionQuarantineCapsule                 = "Attempts a capture with +50% chance. Only usable in combat."
+	ItemDescriptionAccessKey                         = "Opens a locked door. Used up when it does."
+)
+
+var ItemDescriptions = map[ItemType]ItemDescription{
+	ItemNone:              ItemDescriptionNone,
+	ItemIntegrityPatch:    ItemDescriptionIntegrityPatch,
+	ItemFirewallRebuild:   ItemDescriptionFirewallRebuild,
+	ItemPenetrationScript: ItemDescriptionPenetrationScript,
+	ItemQuarantineCapsule: ItemDescriptionQuarantineCapsule,
+	ItemAccessKey:         ItemDescriptionAccessKey,
+}
+
+// ItemTypes is every item in the order they are listed in the inventory.
+var ItemTypes = []ItemType{
+	ItemIntegrityPatch,
+	ItemFirewallRebuild,
+	ItemPenetrationScript,
+	ItemQuarantineCapsule,
+	ItemAccessKey,
+}
+
+const itemRestoreAmount = 10
+const maxItemStack = 9
+const quarantineCapsuleBonus = 0.5
+
+// ItemStack is a number of the same item.
+type ItemStack struct {
+	Type  ItemType
+	Count int
+}
+
+// Inventory holds stacks of items, kept in ItemTypes order, as well as cycles to spend on them.
+type Inventory struct {
+	Items  []ItemStack
+	Cycles int
+}
+
+// ItemHolder is implemented by actors that can carry items.
+type ItemHolder interface {
+	Inventory() *Inventory
+}
+
+// Add adds count of the item, up to maxItemStack. Returns how many were actually added.
+func (inv *Inventory) Add(t ItemType, count int) int {
+	for i, s := range inv.Items {
+		if s.Type == t {
+			if s.Count+count > maxItemStack {
+				count = maxItemStack - s.Count
+			}
+			inv.Items[i].Count += count
+			return count
+		}
+	}
+	if count > maxItemStack {
+		count = maxItemStack
+	}
+	if count <= 0 {
+		return 0
+	}
+	inv.Items = append(inv.Items, ItemStack{Type: t, Count: count})
+	inv.sort()
+	return count
+}
+
+// Remove takes one of the item away. Returns false if there was none.
+func (inv *Inventory) Remove(t ItemType) bool {
+	for i, s := range inv.Items {
+		if s.Type == t {
+			inv.Items[i].Count--
+			if inv.Items[i].Count <= 0 {
+				inv.Items = append(inv.Items[:i], inv.Items[i+1:]...)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func (inv *Inventory) Count(t ItemType) int {
+	for _, s := range inv.Items {
+		if s.Type == t {
+			return s.Count
+		}
+	}
+	return 0
+}
+
+func (inv *Inventory) Empty() bool {
+	return len(inv.Items) == 0
+}
+
+func (inv *Inventory) sort() {
+	var items []ItemStack
+	for _, t := range ItemTypes {
+		for _, s := range inv.Items {
+			if s.Type == t {
+				items = append(items, s)
+			}
+		}
+	}
+	inv.Items = items
+}
+
+// ItemUsableInField returns if the item can be used outside of combat.
+func ItemUsableInField(t ItemType) bool {
+	return t != ItemQuarantineCapsule && t != ItemAccessKey
+}
+
+// ItemUsableInCombat returns if the item shows up in combat's ITEM menu.
+func ItemUsableInCombat(t ItemType) bool {
+	return t != ItemAccessKey
+}
+
+// UseItem applies a restoring item to the target and returns what happened. The item is not removed from any inventory. Returns false if it had no effect.
+func UseItem(t ItemType, target CombatActor) (string, bool) {
+	p, f, i := target.CurrentStats()
+	mp, mf, mi := target.MaxStats()
+	restore := func(cur, max int) int {
+		if cur >= max {
+			return 0
+		}
+		if max-cur < itemRestoreAmount {
+			return max - cur
+		}
+		return itemRestoreAmount
+	}
+	var stat string
+	var v int
+	switch t {
+	case ItemIntegrityPatch:
+		stat = "INTEGRITY"
+		if v = restore(i, mi); v > 0 {
+			target.ApplyBoost(-1, -1, v)
+		}
+	case ItemFirewallRebuild:
+		stat = "FIREWALL"
+		if v = restore(f, mf); v > 0 {
+			target.ApplyBoost(-1, v, -1)
+		}
+	case ItemPenetrationScript:
+		stat = "PENETRATION"
+		if v = restore(p, mp); v > 0 {
+			target.ApplyBoost(v, -1, -1)
+		}
+	default:
+		return fmt.Sprintf("%s does nothing here", t), false
+	}
+	if v <= 0 {
+		return fmt.Sprintf("%s's %s is already full", target.Name(), stat), false
+	}
+	return fmt.Sprintf("%s restores %d %s!", target.Name(), v, stat), true
+}
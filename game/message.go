@@ -16,7 +16,7 @@ type Message struct {
 	Y          int
 	H          float64
 	Font       *res.Font
-	start      time.Time
+	start      int64
 	id         int
 }
 
@@ -0,0 +1,193 @@
+package game
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// visibleAlpha is how visible a tile or actor must be through the darkness for the player to have seen it.
+const visibleAlpha = 0.25
+
+const minimapMaxWidth = mapUIWidth - 8
+const minimapMaxHeight = 90
+const minimapMaxScale = 4
+
+var minimapUIX = 0
+var minimapUIY = 0
+var minimapUIScale = 0
+var minimapUIWidth = 0
+var minimapUIHeight = 0
+
+var minimapWall = color.NRGBA{194, 193, 174, 255}
+var minimapFloor = color.NRGBA{80, 80, 110, 255}
+var minimapPath = color.NRGBA{150, 120, 60, 255}
+var minimapOther = color.NRGBA{80, 180, 180, 255}
+var minimapPlayer = color.NRGBA{255, 255, 50, 255}
+var minimapDoor = color.NRGBA{50, 200, 255, 255}
+var minimapGlitch = color.NRGBA{255, 50, 255, 255}
+
+// minimapScale returns how many pixels each tile of the room takes on the minimap.
+func minimapScale(r *Room) int {
+	w, h := r.Size()
+	if w == 0 || h == 0 {
+		return 1
+	}
+	scale := minimapMaxScale
+	if s := minimapMaxWidth / w; s < scale {
+		scale = s
+	}
+	if s := minimapMaxHeight / h; s < scale {
+		scale = s
+	}
+	if scale < 1 {
+		scale = 1
+	}
+	return scale
+}
+
+// minimapTileColor picks a color based on the family of the tile's sprite, e.g. "haven-wall" is a wall.
+func minimapTileColor(t *Tile) color.NRGBA {
+	if t.BlocksMove {
+		return minimapWall
+	}
+	s := t.SpriteStack.Sprite()
+	switch {
+	case strings.HasSuffix(s, "-wall"):
+		return minimapWall
+	case strings.HasSuffix(s, "-floor"):
+		return minimapFloor
+	case strings.HasSuffix(s, "-path"):
+		return minimapPath
+	}
+	return minimapOther
+}
+
+// drawMinimap draws the current room at the given position. Tiles are only shown once seen, and are dimmed when out of sight.
+func (w *World) drawMinimap(screen *ebiten.Image, x, y int) {
+	r := w.Room
+	scale := minimapScale(r)
+	iw, ih := w.minimapWidth(), w.minimapHeight()
+	if iw <= 0 || ih <= 0 {
+		return
+	}
+
+	if w.minimapImage == nil || w.minimapImage.Bounds().Dx() != iw || w.minimapImage.Bounds().Dy() != ih {
+		w.minimapImage = ebiten.NewImage(iw, ih)
+		w.minimapPixels = make([]byte, iw*ih*4)
+	}
+	pix := w.minimapPixels
+	for i := range pix {
+		pix[i] = 0
+	}
+	plot := func(tx, ty int, c color.NRGBA) {
+		for py := ty * scale; py < (ty+1)*scale && py < ih; py++ {
+			for px := tx * scale; px < (tx+1)*scale && px < iw; px++ {
+				i := (py*iw + px) * 4
+				pix[i] = c.R
+				pix[i+1] = c.G
+				pix[i+2] = c.B
+				pix[i+3] = c.A
+			}
+		}
+	}
+
+	for ty := range r.Tiles {
+		for tx := range r.Tiles[ty] {
+			t := &r.Tiles[ty][tx]
+			if t.SpriteStack == nil || t.Off || !r.seen[ty][tx] {
+				continue
+			}
+			c := minimapTileColor(t)
+			if t.SpriteStack.Alpha < visibleAlpha {
+				c.A = 100
+			}
+			plot(tx, ty, c)
+		}
+	}
+
+	for _, a := range r.Actors {
+		ax, ay, _ := a.Position()
+		switch {
+		case a == w.PlayerActor:
+			plot(ax, ay, minimapPlayer)
+		case a.Glitch():
+			if r.spotted[a] {
+				plot(ax, ay, minimapGlitch)
+			}
+		case a.SpriteStack() != nil && strings.Contains(a.SpriteStack().Sprite(), "door"):
+			if r.spotted[a] {
+				plot(ax, ay, minimapDoor)
+			}
+		}
+	}
+
+	w.minimapImage.WritePixels(pix)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(x), float64(y))
+	screen.DrawImage(w.minimapImage, op)
+
+	minimapUIX, minimapUIY = x, y
+	minimapUIWidth, minimapUIHeight = iw, ih
+	minimapUIScale = scale
+}
+
+// minimapWidth returns how wide the minimap of the current room is.
+func (w *World) minimapWidth() int {
+	if w.Room == nil {
+		return 0
+	}
+	rw, _ := w.Room.Size()
+	width := rw * minimapScale(w.Room)
+	if width > minimapMaxWidth {
+		width = minimapMaxWidth
+	}
+	return width
+}
+
+// minimapHeight returns how tall the minimap of the current room is.
+func (w *World) minimapHeight() int {
+	if w.Room == nil {
+		return 0
+	}
+	_, rh := w.Room.Size()
+	height := rh * minimapScale(w.Room)
+	if height > minimapMaxHeight {
+		height = minimapMaxHeight
+	}
+	return height
+}
+
+// minimapTile returns the tile under the given screen position, if it is on the minimap.
+func minimapTile(x, y int) (int, int, bool) {
+	if minimapUIScale == 0 || x < minimapUIX || y < minimapUIY || x >= minimapUIX+minimapUIWidth || y >= minimapUIY+minimapUIHeight {
+		return 0, 0, false
+	}
+	return (x - minimapUIX) / minimapUIScale, (y - minimapUIY) / minimapUIScale, true
+}
+
+// OverMinimap returns if the given screen position is over the minimap, so clicks there don't also go to the room beneath it.
+func OverMinimap(x, y int) bool {
+	_, _, ok := minimapTile(x, y)
+	return ok
+}
+
+// WalkPlayerTo starts walking the player to the given tile, replacing any walk already underway.
+func (w *World) WalkPlayerTo(x, y int) {
+	w.StopWalking()
+	if w.PlayerActor == nil || w.Room == nil {
+		return
+	}
+	s := NewSequence(WalkTo(w.PlayerActor, x, y))
+	s.Room = w.Room
+	w.walk = w.PlaySequence(s)
+}
+
+// StopWalking stops the player's walk, if any.
+func (w *World) StopWalking() {
+	if w.walk != nil {
+		w.walk.Cancel(w)
+		w.walk = nil
+	}
+}
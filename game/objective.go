@@ -0,0 +1,179 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/kettek/ebihack23/res"
+	"github.com/tinne26/etxt"
+)
+
+type ObjectiveKind string
+
+const (
+	ObjectiveCleanse ObjectiveKind = "cleanse" // Target is a room ID.
+	ObjectiveCapture               = "capture" // Target is a glitch's name.
+	ObjectiveDefeat                = "defeat"  // Target is a glitch's tag. Destroying and capturing both count.
+	ObjectiveReach                 = "reach"   // Target is a room ID.
+)
+
+// Objective is something the player has been told to do. Progress is tracked from world events.
+type Objective struct {
+	ID         string
+	Text       string
+	Kind       ObjectiveKind
+	Target     string
+	Count      int // How many times it must happen. 0 is the same as 1.
+	Progress   int
+	Done       bool
+	OnComplete func(w *World)
+}
+
+func (o *Objective) needed() int {
+	if o.Count <= 0 {
+		return 1
+	}
+	return o.Count
+}
+
+// String returns the objective as shown in the objectives panel.
+func (o *Objective) String() string {
+	if o.needed() > 1 {
+		return fmt.Sprintf("%s (%d/%d)", o.Text, o.Progress, o.needed())
+	}
+	return o.Text
+}
+
+// AddObjective starts tracking an objective. Objectives with the same ID as an existing one are ignored.
+func (w *World) AddObjective(o *Objective) {
+	if w.Objective(o.ID) != nil {
+		return
+	}
+	w.Objectives = append(w.Objectives, o)
+	// Some things may already be done.
+	if o.Kind == ObjectiveReach && w.Visited[o.Target] {
+		w.objectiveEvent(ObjectiveReach, o.Target)
+	}
+}
+
+// Objective returns the objective with the given ID, if there is one.
+func (w *World) Objective(id string) *Objective {
+	for _, o := range w.Objectives {
+		if o.ID == id {
+			return o
+		}
+	}
+	return nil
+}
+
+// objectiveEvent progresses any unfinished objectives waiting on the given event.
+func (w *World) objectiveEvent(kind ObjectiveKind, target string) {
+	for _, o := range w.Objectives {
+		if o.Done || o.Kind != kind || o.Target != target {
+			continue
+		}
+		o.Progress++
+		if o.Progress < o.needed() {
+			continue
+		}
+		o.Done = true
+		res.PlaySound("cleansed")
+		w.Play(nil, ShowMessage(Message{
+			Duration:   3 * time.Second,
+			Color:      color.NRGBA{0, 0, 0, 255},
+			Background: color.NRGBA{255, 255, 255, 255},
+			Text:       "<DONE>\n" + o.Text,
+		}))
+		if o.OnComplete != nil {
+			o.OnComplete(w)
+		}
+	}
+}
+
+type objectiveCue struct {
+	messageCue
+	objective *Objective
+	given     bool
+}
+
+// GiveObjective adds the objective, showing it as an "<ACT>" message.
+func GiveObjective(o *Objective) Cue {
+	return &objectiveCue{
+		messageCue: messageCue{msg: Message{
+			Duration:   3 * time.Second,
+			Color:      color.NRGBA{0, 0, 0, 255},
+			Background: color.NRGBA{255, 255, 255, 255},
+			Text:       "<ACT>\n" + o.Text,
+		}},
+		objective: o,
+	}
+}
+
+func (c *objectiveCue) give(w *World) {
+	if !c.given {
+		c.given = true
+		w.AddObjective(c.objective)
+	}
+}
+
+func (c *objectiveCue) Update(w *World) bool {
+	c.give(w)
+	return c.messageCue.Update(w)
+}
+
+func (c *objectiveCue) Skip(w *World) {
+	c.give(w)
+	c.messageCue.Skip(w)
+}
+
+const objectivesUIWidth = 200
+
+// drawObjectives draws the objectives panel in the top-left.
+func (w *World) drawObjectives(screen *ebiten.Image) {
+	x := 6
+	y := 6
+	h := 20 + len(w.Objectives)*12
+	if len(w.Objectives) == 0 {
+		h += 12
+	}
+
+	vector.DrawFilledRect(screen, float32(x), float32(y), objectivesUIWidth, float32(h), color.NRGBA{19, 19, 97, 200}, false)
+	vector.StrokeRect(screen, float32(x), float32(y), objectivesUIWidth, float32(h), 3, color.NRGBA{194, 193, 174, 255}, true)
+	x += 4
+	y += 3
+
+	res.Text.Utils().StoreState()
+	res.Text.SetAlign(etxt.Left | etxt.Top)
+	res.Text.SetFont(res.DefFont.Font)
+	res.Text.SetSize(float64(res.DefFont.Size))
+	res.Text.SetColor(color.NRGBA{245, 245, 220, 255})
+	res.Text.Draw(screen, "OBJECTIVES", x, y)
+	y += 16
+
+	res.Text.SetFont(res.SmallFont.Font)
+	res.Text.SetSize(float64(res.SmallFont.Size))
+	if len(w.Objectives) == 0 {
+		res.Text.SetColor(color.NRGBA{128, 128, 128, 255})
+		res.Text.Draw(screen, "nothing, yet", x, y)
+	}
+	// Unfinished first.
+	for _, done := range []bool{false, true} {
+		for _, o := range w.Objectives {
+			if o.Done != done {
+				continue
+			}
+			if o.Done {
+				res.Text.SetColor(color.NRGBA{128, 128, 128, 255})
+				res.Text.Draw(screen, "x "+o.String(), x, y)
+			} else {
+				res.Text.SetColor(color.NRGBA{255, 255, 50, 255})
+				res.Text.Draw(screen, "- "+o.String(), x, y)
+			}
+			y += 12
+		}
+	}
+	res.Text.Utils().RestoreState()
+}
@@ -0,0 +1,93 @@
+package game
+
+// Walkable returns true if the tile exists and can be stepped on, ignoring actors.
+func (r *Room) Walkable(x, y int) bool {
+	t := r.GetTile(x, y)
+	return t != nil && t.SpriteStack != nil && !t.Blocks()
+}
+
+// FindPath returns the tiles to step through to get from one tile to another, not including the start. Blocking actors are walked around unless they are at the destination, so a path to a door or a glitch ends by bumping into it. Returns nil if there is no path.
+func (r *Room) FindPath(fromX, fromY, toX, toY int) [][2]int {
+	if fromX == toX && fromY == toY {
+		return nil
+	}
+	if !r.Walkable(toX, toY) && r.GetActor(toX, toY) == nil {
+		return nil
+	}
+	blocked := make(map[[2]int]bool)
+	for _, a := range r.Actors {
+		if !a.Blocks() {
+			continue
+		}
+		x, y, _ := a.Position()
+		blocked[[2]int{x, y}] = true
+	}
+
+	start := [2]int{fromX, fromY}
+	end := [2]int{toX, toY}
+	from := map[[2]int][2]int{start: start}
+	open := [][2]int{start}
+	for len(open) > 0 {
+		p := open[0]
+		open = open[1:]
+		if p == end {
+			var path [][2]int
+			for p != start {
+				path = append([][2]int{p}, path...)
+				p = from[p]
+			}
+			return path
+		}
+		for _, d := range [][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			n := [2]int{p[0] + d[0], p[1] + d[1]}
+			if _, ok := from[n]; ok {
+				continue
+			}
+			if n != end && (!r.Walkable(n[0], n[1]) || blocked[n]) {
+				continue
+			}
+			from[n] = p
+			open = append(open, n)
+		}
+	}
+	return nil
+}
+
+// LineOfSight returns true if nothing that blocks movement lies on the straight line between two tiles. The tiles themselves don't count.
+func (r *Room) LineOfSight(fromX, fromY, toX, toY int) bool {
+	dx := toX - fromX
+	if dx < 0 {
+		dx = -dx
+	}
+	dy := toY - fromY
+	if dy < 0 {
+		dy = -dy
+	}
+	sx, sy := 1, 1
+	if fromX > toX {
+		sx = -1
+	}
+	if fromY > toY {
+		sy = -1
+	}
+	x, y := fromX, fromY
+	err := dx - dy
+	for x != toX || y != toY {
+		e2 := err * 2
+		if e2 > -dy {
+			err -= dy
+			x += sx
+		}
+		if e2 < dx {
+			err += dx
+			y += sy
+		}
+		if x == toX && y == toY {
+			break
+		}
+		if t := r.GetTile(x, y); t != nil && t.BlocksMove && !t.Off {
+			return false
+		}
+	}
+	return true
+}
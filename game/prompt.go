@@ -12,11 +12,19 @@ import (
 	"github.com/tinne26/etxt"
 )
 
+const portraitScale = 3
+const portraitSize = 13 * 2 * portraitScale
+
 // Prompt system. It's kinda jank, but it works well enough for this project.
 type Prompt struct {
-	image      *ebiten.Image
+	image      *ebiten.Image // Made on the first draw, so prompts can be used without a screen.
+	width      int
+	height     int
+	dirty      bool
 	x, y       float64
 	Message    string
+	Speaker    string
+	Portrait   *SpriteStack
 	Items      []string
 	itemBounds []image.Rectangle
 	Selected   int
@@ -26,19 +34,89 @@ type Prompt struct {
 
 func NewPrompt(w, h int, items []string, msg string, cb func(int, string) bool, showExtra bool) *Prompt {
 	p := &Prompt{
-		image:      ebiten.NewImage(w, h),
-		Message:    msg,
-		Items:      items,
-		itemBounds: make([]image.Rectangle, len(items)),
-		Selected:   0,
-		cb:         cb,
-		showExtra:  showExtra,
+		width:     w,
+		height:    h,
+		Message:   msg,
+		Items:     items,
+		Selected:  0,
+		cb:        cb,
+		showExtra: showExtra,
 	}
 	p.Refresh()
 	return p
 }
 
+// SetItems replaces the prompt's items and resets the selection.
+func (p *Prompt) SetItems(items []string) {
+	p.Items = items
+	p.Selected = 0
+	p.Refresh()
+}
+
+// Refresh marks the prompt to be redrawn and works out where its items now are.
 func (p *Prompt) Refresh() {
+	p.dirty = true
+	p.layout()
+}
+
+// textWidth returns how wide the prompt's text can be.
+func (p *Prompt) textWidth() int {
+	tw := p.width - 8
+	if p.Portrait != nil {
+		// Leave room for the portrait in the top-right.
+		tw -= portraitSize
+	}
+	return tw
+}
+
+// layout works out where the prompt's items are, for clicking. It doesn't need the prompt to have been drawn.
+func (p *Prompt) layout() {
+	res.Text.Utils().StoreState()
+	res.Text.SetSize(float64(res.DefFont.Size))
+	res.Text.SetFont(res.DefFont.Font)
+
+	x := 4
+	y := 2
+	tw := p.textWidth()
+	if p.showExtra {
+		y += res.Text.MeasureWithWrap(fmt.Sprintf("ebiOS %s\n", res.EbiOS), tw).IntHeight()
+	}
+	if p.Speaker != "" {
+		y += res.Text.MeasureWithWrap(p.Speaker+"\n", tw).IntHeight()
+	}
+	y += res.Text.MeasureWithWrap(p.Message+"\n", tw).IntHeight()
+
+	// Magic numbers... for now.
+	if y < 50 {
+		y = 50
+	}
+	if p.Portrait != nil && y < portraitSize+8 {
+		y = portraitSize + 8
+	}
+
+	p.itemBounds = make([]image.Rectangle, len(p.Items))
+	for i := range p.Items {
+		s := p.itemText(i)
+		p.itemBounds[i] = image.Rect(x, y, x+res.Text.Measure(s).IntWidth(), y+res.Text.Measure(s).IntHeight())
+		// Ugh, screw it.
+		y += 16
+	}
+	res.Text.Utils().RestoreState()
+}
+
+func (p *Prompt) itemText(i int) string {
+	if p.Selected == i {
+		return "> " + p.Items[i]
+	}
+	return "  " + p.Items[i]
+}
+
+// render redraws the prompt's image.
+func (p *Prompt) render() {
+	if p.image == nil {
+		p.image = ebiten.NewImage(p.width, p.height)
+	}
+	p.dirty = false
 	p.image.Fill(color.NRGBA{66, 66, 60, 200})
 
 	pt := p.image.Bounds().Size()
@@ -47,6 +125,7 @@ func (p *Prompt) Refresh() {
 
 	x := 4
 	y := 2
+	tw := p.textWidth()
 	res.Text.Utils().StoreState()
 	res.Text.SetAlign(etxt.Left | etxt.Top)
 	res.Text.SetSize(float64(res.DefFont.Size))
@@ -56,38 +135,32 @@ func (p *Prompt) Refresh() {
 	if p.showExtra {
 		msg = fmt.Sprintf("ebiOS %s\n", res.EbiOS)
 		res.Text.SetColor(color.NRGBA{219, 86, 32, 200})
-		res.Text.DrawWithWrap(p.image, msg, x, y, pt.X-8)
-		y += res.Text.MeasureWithWrap(msg, pt.X-8).IntHeight()
+		res.Text.DrawWithWrap(p.image, msg, x, y, tw)
+		y += res.Text.MeasureWithWrap(msg, tw).IntHeight()
+	}
+
+	if p.Speaker != "" {
+		msg = p.Speaker + "\n"
+		res.Text.SetColor(color.NRGBA{245, 245, 120, 220})
+		res.Text.DrawWithWrap(p.image, msg, x, y, tw)
+		y += res.Text.MeasureWithWrap(msg, tw).IntHeight()
 	}
 
 	msg = p.Message + "\n"
 	res.Text.SetColor(color.NRGBA{255, 255, 255, 200})
-	res.Text.DrawWithWrap(p.image, msg, x, y, pt.X-8)
-	y += res.Text.MeasureWithWrap(msg, pt.X-8).IntHeight()
-
-	// Magic numbers... for now.
-	if y < 50 {
-		y = 50
-	}
+	res.Text.DrawWithWrap(p.image, msg, x, y, tw)
 
 	res.Text.SetColor(color.NRGBA{0, 255, 44, 200})
-	for i, item := range p.Items {
-		s := item
-		if p.Selected == i {
-			s = "> " + s
-		} else {
-			s = "  " + s
-		}
-
-		p.itemBounds[i] = image.Rect(x, y, x+res.Text.Measure(s).IntWidth(), y+res.Text.Measure(s).IntHeight())
-		res.Text.Draw(p.image, s, x, y)
-		// Ugh, screw it.
-		y += 16
+	for i, b := range p.itemBounds {
+		res.Text.Draw(p.image, p.itemText(i), b.Min.X, b.Min.Y)
 	}
 	res.Text.Utils().RestoreState()
 }
 
 func (p *Prompt) Update() {
+	if p.Portrait != nil {
+		p.Portrait.Rotation += 0.01
+	}
 }
 
 func (p *Prompt) Input(in inputs.Input) {
@@ -106,7 +179,9 @@ func (p *Prompt) Input(in inputs.Input) {
 			p.Selected = len(p.Items) - 1
 		}
 	case inputs.Confirm:
-		p.cb(p.Selected, p.Items[p.Selected])
+		if p.Selected >= 0 && p.Selected < len(p.Items) {
+			p.cb(p.Selected, p.Items[p.Selected])
+		}
 	case inputs.Cancel:
 		p.cb(-1, "")
 	case inputs.Click:
@@ -116,6 +191,7 @@ func (p *Prompt) Input(in inputs.Input) {
 			if x >= float64(b.Min.X) && x <= float64(b.Max.X) && y >= float64(b.Min.Y) && y <= float64(b.Max.Y) {
 				p.Selected = i
 				p.cb(i, p.Items[i])
+				break // The callback may have replaced our items.
 			}
 		}
 	}
@@ -123,7 +199,18 @@ func (p *Prompt) Input(in inputs.Input) {
 }
 
 func (p *Prompt) Draw(screen *ebiten.Image, geom ebiten.GeoM) {
+	if p.image == nil || p.dirty {
+		p.render()
+	}
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Concat(geom)
 	screen.DrawImage(p.image, op)
+
+	if p.Portrait != nil {
+		g := ebiten.GeoM{}
+		g.Scale(portraitScale, portraitScale)
+		g.Translate(float64(p.width-portraitSize/2-8), 48)
+		g.Concat(geom)
+		p.Portrait.DrawIso(screen, g)
+	}
 }
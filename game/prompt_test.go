@@ -0,0 +1,27 @@
+//go:build headless
+
+package game
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/kettek/ebihack23/inputs"
+)
+
+// TestPromptClickBeforeDraw clicks an item of a prompt that has never been drawn.
+func TestPromptClickBeforeDraw(t *testing.T) {
+	picked := -1
+	p := NewPrompt(320, 200, []string{"CANCEL", "OK"}, "Absorb?", func(i int, s string) bool {
+		picked = i
+		return true
+	}, false)
+	b := p.itemBounds[1]
+	if b.Empty() {
+		t.Fatal("item has no bounds before being drawn")
+	}
+	p.Input(inputs.Click{X: float64(b.Min.X + 1), Y: float64(b.Min.Y + 1), Which: ebiten.MouseButtonLeft})
+	if picked != 1 {
+		t.Fatalf("clicked item %d, want 1", picked)
+	}
+}
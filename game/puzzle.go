@@ -0,0 +1,78 @@
+package game
+
+import (
+	"time"
+
+	"github.com/kettek/ebihack23/commands"
+	"github.com/kettek/ebihack23/res"
+)
+
+// Pushable is implemented by actors that are shoved along when the player steps into them, rather than interacted with.
+type Pushable interface {
+	Pushable() bool
+}
+
+// Switchable is implemented by actors that change with story flags, such as gates and switches. SetSwitched is called every update with whether all of the actor's flag conditions pass.
+type Switchable interface {
+	Switches() []string
+	SetSwitched(on bool)
+}
+
+// push tries to shove the actor one tile over. Returns false if the tile beyond is blocked or taken.
+func (r *Room) push(w *World, a, pusher Actor, dx, dy int) bool {
+	x, y, _ := a.Position()
+	x += dx
+	y += dy
+	if !r.Walkable(x, y) || r.GetActor(x, y) != nil {
+		if w.PlayerActor == pusher {
+			px, py, _ := pusher.Position()
+			r.TileMessage(Message{Text: "it won't budge", Duration: 1 * time.Second, Font: &res.SmallFont, X: px, Y: py})
+		}
+		res.PlaySound("bump")
+		return false
+	}
+	a.Command(commands.Step{X: dx, Y: dy})
+	pusher.Command(commands.Step{X: dx, Y: dy})
+	res.PlaySound("step")
+	return true
+}
+
+// NewPlateTrigger creates a pressure plate at the given tile. The flag is set while anything that blocks, such as a pushed block or the player, stands on it. Leaving the room counts as stepping off of it.
+func NewPlateTrigger(x, y int, flag string) *Trigger {
+	t := NewTileTrigger(x, y)
+	t.Tag = flag
+	t.Filter = func(a Actor) bool {
+		return a.Blocks()
+	}
+	t.OnEnter = func(w *World, r *Room, t *Trigger, a Actor) {
+		if !w.Flag(flag) {
+			w.SetFlag(flag, true)
+			res.PlaySound("poweron")
+		}
+	}
+	t.OnLeave = func(w *World, r *Room, t *Trigger, a Actor) {
+		if len(t.Inside()) == 0 && w.Flag(flag) {
+			w.SetFlag(flag, false)
+			res.PlaySound("poweroff")
+		}
+	}
+	return t
+}
+
+// updateSwitches brings switched tiles and actors in line with the world's flags.
+func (r *Room) updateSwitches(w *World) {
+	for i := range r.Tiles {
+		for j := range r.Tiles[i] {
+			t := &r.Tiles[i][j]
+			if t.Switches == nil {
+				continue
+			}
+			t.Off = !w.CheckFlags(t.Switches)
+		}
+	}
+	for _, a := range r.Actors {
+		if s, ok := a.(Switchable); ok {
+			s.SetSwitched(w.CheckFlags(s.Switches()))
+		}
+	}
+}
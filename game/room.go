@@ -18,6 +18,7 @@ type Room struct {
 	Actors          []Actor
 	PendingCommands []ActorCommand
 	TileMessages    []Message
+	Triggers        []*Trigger
 	Color           color.NRGBA
 	targetColor     color.NRGBA
 	Darkness        float64
@@ -30,24 +31,30 @@ type Room struct {
 	OnEnter         func(*World, *Room)
 	OnLeave         func(*World, *Room)
 	OnTurn          func(*World, *Room)
-	RoutineChan     chan func() bool
-	RoutineChans    []func() bool
 	Song            string
 	turn            int
+	ID              string // Registry name the room was built from.
 	Name            string
 	Glitches        int
 	MaxGlitches     int
+	clock           *Clock
+	seen            [][]bool       // Tiles the player has seen, for the minimap.
+	spotted         map[Actor]bool // Actors the player has seen.
 }
 
-func NewRoom(w, h int) *Room {
+// NewRoom makes an empty room that runs off of the given clock, which should be the world's.
+func NewRoom(w, h int, clock *Clock) *Room {
 	r := &Room{
-		iso:         true,
-		RoutineChan: make(chan func() bool),
+		iso:     true,
+		clock:   clock,
+		spotted: make(map[Actor]bool),
 	}
 
 	r.Tiles = make([][]Tile, h)
+	r.seen = make([][]bool, h)
 	for i := range r.Tiles {
 		r.Tiles[i] = make([]Tile, w)
+		r.seen[i] = make([]bool, w)
 		for j := range r.Tiles[i] {
 			//r.Tiles[i][j].ticker = rand.Intn(100)
 			r.Tiles[i][j].Ticker = j*h + j
@@ -81,23 +88,6 @@ func (r *Room) UpdateGlitchion() {
 }
 
 func (r *Room) Update(w *World) []commands.Command {
-	// Routine routines.
-	for done := false; !done; {
-		select {
-		case fnc := <-r.RoutineChan:
-			r.RoutineChans = append(r.RoutineChans, fnc)
-		default:
-			done = true
-		}
-	}
-	routines := r.RoutineChans[:0]
-	for _, ch := range r.RoutineChans {
-		if !ch() {
-			routines = append(routines, ch)
-		}
-	}
-	r.RoutineChans = routines
-
 	if r.colorTicker > 0 {
 		ratio := float64(r.colorTicker) / 60
 
@@ -132,6 +122,9 @@ func (r *Room) Update(w *World) []commands.Command {
 				x, y, _ := w.PlayerActor.Position()
 				if r.Tiles[i][j].SpriteStack != nil {
 					r.Tiles[i][j].SpriteStack.Alpha = 1.0 - float32((x-j)*(x-j)+(y-i)*(y-i))/100*float32(r.Darkness)
+					if r.Tiles[i][j].SpriteStack.Alpha >= visibleAlpha {
+						r.seen[i][j] = true
+					}
 				}
 			}
 		}
@@ -140,7 +133,7 @@ func (r *Room) Update(w *World) []commands.Command {
 	// Routine small messages.
 	messages := r.TileMessages[:0]
 	for _, m := range r.TileMessages {
-		if time.Since(m.start) < m.Duration {
+		if !r.clock.Elapsed(m.start, m.Duration) {
 			messages = append(messages, m)
 		}
 	}
@@ -163,12 +156,24 @@ func (r *Room) Update(w *World) []commands.Command {
 		if w.PlayerActor != nil {
 			j, i, _ := a.Position()
 			x, y, _ := w.PlayerActor.Position()
+			if h, ok := a.(interface{ Hidden() bool }); ok && h.Hidden() {
+				continue
+			}
 			if a.SpriteStack() != nil {
 				a.SpriteStack().Alpha = 1.0 - float32((x-j)*(x-j)+(y-i)*(y-i))/100*float32(r.Darkness)
+				if a.SpriteStack().Alpha >= visibleAlpha {
+					if a.Glitch() && !r.spotted[a] {
+						w.Glitchdex.See(a, r)
+					}
+					r.spotted[a] = true
+				}
 			}
 		}
 	}
 
+	r.updateTriggers(w)
+	r.updateSwitches(w)
+
 	if w.PlayerActor != nil && w.PlayerActor.Ready() {
 		for _, a := range r.Actors {
 			a.SetReady(false)
@@ -183,6 +188,7 @@ func (r *Room) Update(w *World) []commands.Command {
 		if r.OnTurn != nil {
 			r.OnTurn(w, r)
 		}
+		r.turnTriggers(w)
 	}
 
 	// Resort actors by their Z + X - Y position.
@@ -225,7 +231,8 @@ func (r *Room) HandlePendingCommands(w *World) (results []commands.Command) {
 	// Resolve potential collisions first.
 	var collidedActors []Actor
 	var collisionResults []commands.Command
-	for _, cmd := range r.PendingCommands {
+	interacted := make(map[int]Actor) // Who each pending step bumped into.
+	for i, cmd := range r.PendingCommands {
 		a := cmd.Actor
 		bail := false
 		for _, actor := range collidedActors {
@@ -244,6 +251,14 @@ func (r *Room) HandlePendingCommands(w *World) (results []commands.Command) {
 			y := ay + c.Y
 			// First check if an actor is there.
 			if actor := r.GetActor(x, y); actor != nil && actor != cmd.Actor {
+				// Shove anything pushable along, if the player is the one stepping into it.
+				if p, ok := actor.(Pushable); ok && p.Pushable() && w.PlayerActor == a {
+					r.push(w, actor, a, c.X, c.Y)
+					collidedActors = append(collidedActors, actor)
+					collidedActors = append(collidedActors, a)
+					continue
+				}
+				interacted[i] = actor
 				if cmd := actor.Interact(w, r, cmd.Actor); cmd != nil {
 					collisionResults = append(collisionResults, cmd)
 					collidedActors = append(collidedActors, actor)
@@ -270,7 +285,7 @@ func (r *Room) HandlePendingCommands(w *World) (results []commands.Command) {
 	}
 	results = append(results, collisionResults...)
 
-	for _, cmd := range r.PendingCommands {
+	for i, cmd := range r.PendingCommands {
 		a := cmd.Actor
 		bail := false
 		for _, actor := range collidedActors {
@@ -290,18 +305,21 @@ func (r *Room) HandlePendingCommands(w *World) (results []commands.Command) {
 			cmd.Actor.Command(commands.Face{X: x, Y: y})
 			// Check if our destination is blocked.
 			if actor := r.GetActor(x, y); actor != nil && actor != cmd.Actor {
-				if cmd := actor.Interact(w, r, cmd.Actor); cmd != nil {
-					results = append(results, cmd)
-					collidedActors = append(collidedActors, actor)
-					collidedActors = append(collidedActors, a)
-				} else if actor != nil && w.PlayerActor == a && actor.Blocks() {
-					var s string
-					if actor.Name() == "" {
-						s = "something"
-					} else {
-						s = fmt.Sprintf("<%s>", actor.Name())
+				// Anything bumped into while resolving collisions has already had its say.
+				if interacted[i] != actor {
+					if cmd := actor.Interact(w, r, cmd.Actor); cmd != nil {
+						results = append(results, cmd)
+						collidedActors = append(collidedActors, actor)
+						collidedActors = append(collidedActors, a)
+					} else if actor != nil && w.PlayerActor == a && actor.Blocks() {
+						var s string
+						if actor.Name() == "" {
+							s = "something"
+						} else {
+							s = fmt.Sprintf("<%s>", actor.Name())
+						}
+						r.TileMessage(Message{Text: fmt.Sprintf("%s is there...\n", s), Duration: 3 * time.Second, Font: &res.SmallFont, X: ax, Y: ay})
 					}
-					r.TileMessage(Message{Text: fmt.Sprintf("%s is there...\n", s), Duration: 3 * time.Second, Font: &res.SmallFont, X: ax, Y: ay})
 				}
 				if !actor.Blocks() {
 					cmd.Actor.Command(c)
@@ -315,7 +333,7 @@ func (r *Room) HandlePendingCommands(w *World) (results []commands.Command) {
 					if w.PlayerActor == a {
 						r.TileMessage(Message{Text: "the void gazes at you", Duration: 1 * time.Second, Font: &res.SmallFont, X: ax, Y: ay})
 					}
-				} else if !tile.BlocksMove {
+				} else if !tile.Blocks() {
 					cmd.Actor.Command(c)
 				} else {
 					if w.PlayerActor == a {
@@ -393,7 +411,7 @@ func (r *Room) GetTilePositionGeoM(x, y int) (g ebiten.GeoM, ratio float64) {
 func (r *Room) Draw(screen *ebiten.Image, geom ebiten.GeoM) {
 	for i := range r.Tiles {
 		for j := range r.Tiles[i] {
-			if r.Tiles[i][j].SpriteStack == nil {
+			if r.Tiles[i][j].SpriteStack == nil || r.Tiles[i][j].Off {
 				continue
 			}
 			g, ratio := r.GetTilePositionGeoM(j, i)
@@ -413,7 +431,7 @@ func (r *Room) Draw(screen *ebiten.Image, geom ebiten.GeoM) {
 		g.Translate(0, -3*float64(len(r.TileMessages)-i))
 
 		// Float it upwards.
-		delta := float64(time.Since(m.start)) / float64(m.Duration) * 3
+		delta := float64(r.clock.Since(m.start)) / float64(DurationTicks(m.Duration)) * 3
 		g.Translate(0, -delta)
 
 		g.Concat(geom)
@@ -478,7 +496,7 @@ func (r *Room) SetColor(c color.NRGBA) {
 }
 
 func (r *Room) TileMessage(m Message) {
-	m.start = time.Now()
+	m.start = r.clock.Now()
 	if m.Color.A == 0 {
 		m.Color = color.NRGBA{255 - r.Color.R, 255 - r.Color.G, 255 - r.Color.B, 255}
 	}
@@ -488,81 +506,23 @@ func (r *Room) TileMessage(m Message) {
 	r.TileMessages = append(r.TileMessages, m)
 }
 
-func (r *Room) TileMessageR(m Message) chan bool {
-	done := make(chan bool)
-	m.start = time.Now()
-	if m.Color.A == 0 {
-		m.Color = color.NRGBA{0, 0, 0, 255}
-	}
-	if m.Font == nil {
-		m.Font = &res.DefFont
-	}
-	fnc := func() bool {
-		r.TileMessages = append(r.TileMessages, m)
-		done <- true
-		return true
-	}
-	r.RoutineChan <- fnc
-	return done
-}
-
-func (r *Room) FuncR(fnc func()) chan bool {
-	done := make(chan bool)
-	f := func() bool {
-		fnc()
-		done <- true
-		return true
-	}
-	r.RoutineChan <- f
-	return done
-}
-
-func (r *Room) DropInR() chan bool {
-	done := make(chan bool)
-
-	count := 0
-
-	fnc := func() bool {
-		count++
-		if count >= 60 {
-			for i := range r.Tiles {
-				for j := range r.Tiles[i] {
-					if r.Tiles[i][j].SpriteStack == nil {
-						continue
-					}
-					r.Tiles[i][j].SpriteStack.LayerDistance = -1
-					r.Tiles[i][j].SpriteStack.Alpha = 1
-				}
-			}
-			for _, a := range r.Actors {
-				if sp := a.SpriteStack(); sp != nil {
-					sp.LayerDistance = -1
-					sp.Alpha = 1
-				}
-			}
-			done <- true
-			return true
-		} else {
-			for i := range r.Tiles {
-				for j := range r.Tiles[i] {
-					if r.Tiles[i][j].SpriteStack == nil {
-						continue
-					}
-					r.Tiles[i][j].SpriteStack.LayerDistance = -1 + (1.0-float64(count)/60)*-10
-					r.Tiles[i][j].SpriteStack.Alpha = float32(count) / 60
-				}
-			}
-			for _, a := range r.Actors {
-				if sp := a.SpriteStack(); sp != nil {
-					sp.LayerDistance = -1 + (1.0-float64(count)/60)*-10
-					sp.Alpha = float32(count) / 60
-				}
+// setDrop sets the layer distance and alpha of all tiles and actors. Used for dropping the room in.
+func (r *Room) setDrop(distance float64, alpha float32) {
+	for i := range r.Tiles {
+		for j := range r.Tiles[i] {
+			if r.Tiles[i][j].SpriteStack == nil {
+				continue
 			}
-			return false
+			r.Tiles[i][j].SpriteStack.LayerDistance = distance
+			r.Tiles[i][j].SpriteStack.Alpha = alpha
+		}
+	}
+	for _, a := range r.Actors {
+		if sp := a.SpriteStack(); sp != nil {
+			sp.LayerDistance = distance
+			sp.Alpha = alpha
 		}
 	}
-	r.RoutineChan <- fnc
-	return done
 }
 
 func (r *Room) GetActorByTag(tag string) Actor {
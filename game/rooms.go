@@ -1,5 +1,5 @@
 package game
 
 type Rooms interface {
-	BuildRoom(name string) *Room
+	BuildRoom(name string, clock *Clock) (*Room, error)
 }
@@ -0,0 +1,518 @@
+package game
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/kettek/ebihack23/commands"
+	"github.com/kettek/ebihack23/inputs"
+	"github.com/kettek/ebihack23/res"
+)
+
+// TicksPerSecond is how many times World.Update is expected to be called per second.
+const TicksPerSecond = 60
+
+// DurationTicks converts a duration into world ticks.
+func DurationTicks(d time.Duration) int {
+	return int(d * TicksPerSecond / time.Second)
+}
+
+// Cue is a single step of a Sequence. Update is called once per tick and returns true once the cue is finished.
+type Cue interface {
+	Update(w *World) bool
+}
+
+// Skipper is implemented by cues that need to apply their outcome when skipped.
+type Skipper interface {
+	Skip(w *World)
+}
+
+// Stopper is implemented by cues that need to clean up after themselves when cancelled.
+type Stopper interface {
+	Stop(w *World)
+}
+
+// Sequence is a list of cues that are run one after another, driven by World.Update. This replaces the old goroutine + channel approach to scripting.
+type Sequence struct {
+	Room      *Room // If set, the sequence is cancelled when the room is left.
+	Skippable bool
+	OnDone    func(w *World)
+	cues      []Cue
+	index     int
+	done      bool
+}
+
+func NewSequence(cues ...Cue) *Sequence {
+	return &Sequence{
+		cues: cues,
+	}
+}
+
+// Then appends more cues to the end of the sequence.
+func (s *Sequence) Then(cues ...Cue) *Sequence {
+	s.cues = append(s.cues, cues...)
+	return s
+}
+
+func (s *Sequence) Done() bool {
+	return s.done
+}
+
+// Update runs the current cue, moving on to the following ones as they finish. Returns true when the sequence is done.
+func (s *Sequence) Update(w *World) bool {
+	if s.done {
+		return true
+	}
+	for s.index < len(s.cues) {
+		if !s.cues[s.index].Update(w) {
+			return false
+		}
+		s.index++
+	}
+	s.finish(w)
+	return true
+}
+
+// Skip applies the outcome of all remaining cues and finishes the sequence.
+func (s *Sequence) Skip(w *World) {
+	if s.done {
+		return
+	}
+	for ; s.index < len(s.cues); s.index++ {
+		if c, ok := s.cues[s.index].(Skipper); ok {
+			c.Skip(w)
+		}
+	}
+	s.finish(w)
+}
+
+// Cancel stops the sequence where it is without applying any of the remaining cues.
+func (s *Sequence) Cancel(w *World) {
+	if s.done {
+		return
+	}
+	if s.index < len(s.cues) {
+		if c, ok := s.cues[s.index].(Stopper); ok {
+			c.Stop(w)
+		}
+	}
+	s.done = true
+}
+
+func (s *Sequence) finish(w *World) {
+	s.done = true
+	if s.OnDone != nil {
+		s.OnDone(w)
+	}
+}
+
+// Play starts a new skippable sequence. If room is not nil, the sequence is cancelled when that room is left.
+func (w *World) Play(room *Room, cues ...Cue) *Sequence {
+	s := NewSequence(cues...)
+	s.Room = room
+	s.Skippable = true
+	return w.PlaySequence(s)
+}
+
+func (w *World) PlaySequence(s *Sequence) *Sequence {
+	w.Sequences = append(w.Sequences, s)
+	return s
+}
+
+// SkipSequences skips all running skippable sequences. Returns true if anything was skipped.
+func (w *World) SkipSequences() bool {
+	skipped := false
+	for _, s := range w.Sequences {
+		if s.Skippable && !s.done {
+			s.Skip(w)
+			skipped = true
+		}
+	}
+	return skipped
+}
+
+// CancelSequences cancels all sequences bound to the given room.
+func (w *World) CancelSequences(room *Room) {
+	for _, s := range w.Sequences {
+		if s.Room == room {
+			s.Cancel(w)
+		}
+	}
+}
+
+func (w *World) updateSequences() {
+	// Sequences may start other sequences, so only process what we have at the start.
+	count := len(w.Sequences)
+	for i := 0; i < count; i++ {
+		w.Sequences[i].Update(w)
+	}
+	sequences := w.Sequences[:0]
+	for _, s := range w.Sequences {
+		if !s.done {
+			sequences = append(sequences, s)
+		}
+	}
+	w.Sequences = sequences
+}
+
+type waitCue struct {
+	ticks   int
+	start   int64
+	started bool
+}
+
+// Wait waits the given amount of ticks.
+func Wait(ticks int) Cue {
+	return &waitCue{ticks: ticks}
+}
+
+func (c *waitCue) Update(w *World) bool {
+	if !c.started {
+		c.started = true
+		c.start = w.Clock.Now() - 1 // The tick we start on counts.
+	}
+	return w.Clock.Since(c.start) >= int64(c.ticks)
+}
+
+type doCue struct {
+	fnc func(w *World)
+}
+
+// Do calls fnc and immediately continues. It is also called when skipped.
+func Do(fnc func(w *World)) Cue {
+	return &doCue{fnc: fnc}
+}
+
+func (c *doCue) Update(w *World) bool {
+	c.fnc(w)
+	return true
+}
+
+func (c *doCue) Skip(w *World) {
+	c.fnc(w)
+}
+
+type waitForCue struct {
+	sequences []*Sequence
+}
+
+// WaitFor waits until all of the given sequences are done.
+func WaitFor(sequences ...*Sequence) Cue {
+	return &waitForCue{sequences: sequences}
+}
+
+func (c *waitForCue) Update(w *World) bool {
+	for _, s := range c.sequences {
+		if !s.done {
+			return false
+		}
+	}
+	return true
+}
+
+type messageCue struct {
+	msg      Message
+	started  bool
+	duration int
+}
+
+// ShowMessage shows a big message and waits for it to fade out.
+func ShowMessage(msg Message) Cue {
+	return &messageCue{msg: msg}
+}
+
+func (c *messageCue) Update(w *World) bool {
+	if !c.started {
+		c.started = true
+		c.msg.id = messageID
+		messageID++
+		if c.msg.Color.A == 0 {
+			c.msg.Color = color.NRGBA{0, 0, 0, 255}
+		}
+		if c.msg.Font == nil {
+			c.msg.Font = &res.DefFont
+		}
+		c.msg.start = w.Clock.Now() - 1
+		c.duration = DurationTicks(c.msg.Duration)
+		if w.SkipMessages {
+			c.duration = 0
+		}
+		w.Messages = append(w.Messages, c.msg)
+	}
+	elapsed := int(w.Clock.Since(c.msg.start))
+
+	if m := w.message(c.msg.id); m != nil {
+		fade := DurationTicks(200 * time.Millisecond)
+		ratio := 1.0
+		if elapsed < fade {
+			ratio = float64(elapsed) / float64(fade)
+		} else if elapsed > c.duration-fade {
+			ratio = float64(c.duration-elapsed) / float64(fade)
+		}
+		if ratio < 0 {
+			ratio = 0
+		}
+		m.Color.A = uint8(ratio * 200)
+		m.Background.A = uint8(ratio * 200)
+		m.H = ratio
+	}
+
+	if elapsed >= c.duration {
+		w.removeMessage(c.msg.id)
+		return true
+	}
+	return false
+}
+
+func (c *messageCue) Skip(w *World) {
+	c.Stop(w)
+}
+
+func (c *messageCue) Stop(w *World) {
+	if c.started {
+		w.removeMessage(c.msg.id)
+	}
+}
+
+func (w *World) message(id int) *Message {
+	for i := range w.Messages {
+		if w.Messages[i].id == id {
+			return &w.Messages[i]
+		}
+	}
+	return nil
+}
+
+func (w *World) removeMessage(id int) {
+	for i := range w.Messages {
+		if w.Messages[i].id == id {
+			w.Messages = append(w.Messages[:i], w.Messages[i+1:]...)
+			return
+		}
+	}
+}
+
+type moveCue struct {
+	actor   Actor
+	x, y    int
+	waiting int
+	lastX   int
+	lastY   int
+}
+
+// MoveActor walks the actor, one step at a time, to the given tile. It gives up if the actor gets stuck.
+func MoveActor(actor Actor, x, y int) Cue {
+	return &moveCue{actor: actor, x: x, y: y}
+}
+
+func (c *moveCue) Update(w *World) bool {
+	ax, ay, _ := c.actor.Position()
+	if ax == c.x && ay == c.y {
+		return true
+	}
+	if c.waiting > 0 {
+		if ax != c.lastX || ay != c.lastY {
+			c.waiting = 0
+		} else {
+			c.waiting--
+			if c.waiting == 0 {
+				// Couldn't move, so give up.
+				return true
+			}
+			return false
+		}
+	}
+	var step commands.Step
+	if ax < c.x {
+		step.X = 1
+	} else if ax > c.x {
+		step.X = -1
+	} else if ay < c.y {
+		step.Y = 1
+	} else if ay > c.y {
+		step.Y = -1
+	}
+	c.actor.Command(step)
+	c.lastX, c.lastY = ax, ay
+	c.waiting = 30
+	return false
+}
+
+func (c *moveCue) Skip(w *World) {
+	_, _, z := c.actor.Position()
+	c.actor.SetPosition(c.x, c.y, z)
+}
+
+type panCue struct {
+	x, y    int
+	ticks   int
+	start   int64
+	started bool
+}
+
+// PanCamera moves the camera to the given tile and holds it there for the given amount of ticks before returning it to the player.
+func PanCamera(x, y int, ticks int) Cue {
+	return &panCue{x: x, y: y, ticks: ticks}
+}
+
+func (c *panCue) Update(w *World) bool {
+	if !c.started {
+		c.started = true
+		c.start = w.Clock.Now() - 1
+		w.focusX, w.focusY = c.x, c.y
+		w.focused = true
+	}
+	if w.Clock.Since(c.start) >= int64(c.ticks) {
+		w.focused = false
+		return true
+	}
+	return false
+}
+
+func (c *panCue) Skip(w *World) {
+	c.Stop(w)
+}
+
+func (c *panCue) Stop(w *World) {
+	if c.started {
+		w.focused = false
+	}
+}
+
+type colorCue struct {
+	room  *Room
+	color color.NRGBA
+	fade  bool
+}
+
+// SetRoomColor fades the room's color to the given color.
+func SetRoomColor(r *Room, c color.NRGBA) Cue {
+	return &colorCue{room: r, color: c, fade: true}
+}
+
+func (c *colorCue) Update(w *World) bool {
+	if c.fade {
+		c.room.SetColor(c.color)
+	} else {
+		c.room.Color = c.color
+	}
+	return true
+}
+
+func (c *colorCue) Skip(w *World) {
+	c.room.Color = c.color
+	c.room.colorTicker = 0
+}
+
+type parallelCue struct {
+	cues []Cue
+	done []bool
+}
+
+// Parallel runs all of the given cues at the same time, finishing when all of them are done.
+func Parallel(cues ...Cue) Cue {
+	return &parallelCue{cues: cues, done: make([]bool, len(cues))}
+}
+
+func (c *parallelCue) Update(w *World) bool {
+	finished := true
+	for i, cue := range c.cues {
+		if c.done[i] {
+			continue
+		}
+		if cue.Update(w) {
+			c.done[i] = true
+		} else {
+			finished = false
+		}
+	}
+	return finished
+}
+
+func (c *parallelCue) Skip(w *World) {
+	for i, cue := range c.cues {
+		if c.done[i] {
+			continue
+		}
+		if s, ok := cue.(Skipper); ok {
+			s.Skip(w)
+		}
+	}
+}
+
+func (c *parallelCue) Stop(w *World) {
+	for i, cue := range c.cues {
+		if c.done[i] {
+			continue
+		}
+		if s, ok := cue.(Stopper); ok {
+			s.Stop(w)
+		}
+	}
+}
+
+type dropInCue struct {
+	room  *Room
+	count int
+}
+
+// DropIn drops the room's tiles and actors into place.
+func DropIn(r *Room) Cue {
+	return &dropInCue{room: r}
+}
+
+func (c *dropInCue) Update(w *World) bool {
+	c.count++
+	if c.count >= 60 {
+		c.Skip(w)
+		return true
+	}
+	c.room.setDrop(-1+(1.0-float64(c.count)/60)*-10, float32(c.count)/60)
+	return false
+}
+
+func (c *dropInCue) Skip(w *World) {
+	c.room.setDrop(-1, 1)
+}
+
+type walkCue struct {
+	actor   Actor
+	x, y    int
+	waiting int
+	lastX   int
+	lastY   int
+}
+
+// WalkTo walks the actor along a path to the given tile, taking turns as if each step was input. The path is found again after every step, so it goes around anything that gets in the way. It stops if there is no path, combat starts, or a prompt opens.
+func WalkTo(actor Actor, x, y int) Cue {
+	return &walkCue{actor: actor, x: x, y: y}
+}
+
+func (c *walkCue) Update(w *World) bool {
+	if w.Combat != nil || len(w.Prompts) > 0 || w.Room == nil {
+		return true
+	}
+	ax, ay, _ := c.actor.Position()
+	if c.waiting > 0 {
+		if ax == c.lastX && ay == c.lastY {
+			c.waiting--
+			// Give up if we didn't go anywhere.
+			return c.waiting == 0
+		}
+		c.waiting = 0
+	}
+	path := w.Room.FindPath(ax, ay, c.x, c.y)
+	if len(path) == 0 {
+		return true
+	}
+	if !c.actor.Input(inputs.Direction{X: path[0][0] - ax, Y: path[0][1] - ay}) {
+		return true
+	}
+	if len(path) == 1 && w.Room.GetActor(c.x, c.y) != nil {
+		// Bumped into whatever is at the destination, so we're done.
+		return true
+	}
+	c.lastX, c.lastY = ax, ay
+	c.waiting = 30
+	return false
+}
@@ -0,0 +1,184 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/kettek/ebihack23/res"
+)
+
+// ItemPrices are what items cost when a shop doesn't set its own price. Items sell for half of the price. Items without a price, such as keys, can't be sold.
+var ItemPrices = map[ItemType]int{
+	ItemIntegrityPatch:    6,
+	ItemFirewallRebuild:   6,
+	ItemPenetrationScript: 6,
+	ItemQuarantineCapsule: 15,
+}
+
+// ShopStock is a single item a shop sells.
+type ShopStock struct {
+	Item  ItemType
+	Price int
+	Count int // How many are left. Less than 0 never runs out.
+}
+
+// Shop is a vendor terminal's stock. Stock is kept between visits, so it should be created once per vendor.
+type Shop struct {
+	Name     string
+	Greeting string
+	Stock    []*ShopStock
+	OnEnd    func(w *World)
+}
+
+// price returns what the shop charges for an item.
+func (s *Shop) price(t ItemType) int {
+	for _, st := range s.Stock {
+		if st.Item == t && st.Price > 0 {
+			return st.Price
+		}
+	}
+	return ItemPrices[t]
+}
+
+// sellPrice returns what the shop pays for an item.
+func (s *Shop) sellPrice(t ItemType) int {
+	if s.price(t) == 0 {
+		return 0
+	}
+	p := s.price(t) / 2
+	if p < 1 {
+		p = 1
+	}
+	return p
+}
+
+// OpenShop shows the shop's buy and sell prompts to the player.
+func (w *World) OpenShop(s *Shop) {
+	holder, ok := w.PlayerActor.(ItemHolder)
+	if !ok {
+		return
+	}
+	inv := holder.Inventory()
+	message := func() string {
+		msg := s.Greeting
+		if msg == "" {
+			msg = "welcome."
+		}
+		return fmt.Sprintf("%s\n%s\nCYCLES: %d", s.Name, msg, inv.Cycles)
+	}
+
+	// Keep the main prompt's cycles up to date once the buy or sell prompt is closed.
+	var main *Prompt
+	refresh := func() {
+		main.Message = message()
+		main.Refresh()
+	}
+
+	w.AddPrompt([]string{"BUY", "SELL", "LEAVE"}, message(), func(i int, str string) bool {
+		switch str {
+		case "BUY":
+			w.shopBuy(s, inv, refresh)
+			return false
+		case "SELL":
+			w.shopSell(s, inv, refresh)
+			return false
+		}
+		if s.OnEnd != nil {
+			s.OnEnd(w)
+		}
+		return true
+	}, false)
+	main = w.Prompts[len(w.Prompts)-1]
+}
+
+func (w *World) shopBuy(s *Shop, inv *Inventory, done func()) {
+	items := func() (items []string) {
+		for _, st := range s.Stock {
+			text := fmt.Sprintf("%s %dc", st.Item, s.price(st.Item))
+			if st.Count == 0 {
+				text += " (sold out)"
+			} else if st.Count > 0 {
+				text += fmt.Sprintf(" (%d)", st.Count)
+			}
+			items = append(items, text)
+		}
+		return append(items, "BACK")
+	}
+	message := func() string {
+		return fmt.Sprintf("buy what?\nCYCLES: %d", inv.Cycles)
+	}
+
+	var p *Prompt
+	w.AddPrompt(items(), message(), func(i int, str string) bool {
+		if i < 0 || i >= len(s.Stock) {
+			done()
+			return true
+		}
+		st := s.Stock[i]
+		price := s.price(st.Item)
+		switch {
+		case st.Count == 0:
+			p.Message = "sold out."
+			res.PlaySound("miss")
+		case inv.Cycles < price:
+			p.Message = fmt.Sprintf("not enough cycles.\nCYCLES: %d", inv.Cycles)
+			res.PlaySound("miss")
+		case inv.Add(st.Item, 1) == 0:
+			p.Message = fmt.Sprintf("can't carry more %s.\nCYCLES: %d", st.Item, inv.Cycles)
+			res.PlaySound("miss")
+		default:
+			inv.Cycles -= price
+			if st.Count > 0 {
+				st.Count--
+			}
+			p.Message = message()
+			res.PlaySound("slurp")
+		}
+		p.SetItems(items())
+		p.Selected = i
+		p.Refresh()
+		return false
+	}, false)
+	p = w.Prompts[len(w.Prompts)-1]
+}
+
+func (w *World) shopSell(s *Shop, inv *Inventory, done func()) {
+	items := func() (items []string) {
+		for _, st := range inv.Items {
+			items = append(items, fmt.Sprintf("%s x%d %dc", st.Type, st.Count, s.sellPrice(st.Type)))
+		}
+		return append(items, "BACK")
+	}
+	message := func() string {
+		return fmt.Sprintf("sell what?\nCYCLES: %d", inv.Cycles)
+	}
+
+	var p *Prompt
+	w.AddPrompt(items(), message(), func(i int, str string) bool {
+		if i < 0 || i >= len(inv.Items) {
+			done()
+			return true
+		}
+		t := inv.Items[i].Type
+		if s.sellPrice(t) == 0 {
+			p.Message = fmt.Sprintf("%s isn't for sale.\nCYCLES: %d", t, inv.Cycles)
+			res.PlaySound("miss")
+		} else if inv.Remove(t) {
+			inv.Cycles += s.sellPrice(t)
+			// Sold items go back into the stock, if the shop keeps count of them.
+			for _, st := range s.Stock {
+				if st.Item == t && st.Count >= 0 {
+					st.Count++
+				}
+			}
+			res.PlaySound("slurp")
+			p.Message = message()
+		}
+		p.SetItems(items())
+		if i < len(inv.Items) {
+			p.Selected = i
+		}
+		p.Refresh()
+		return false
+	}, false)
+	p = w.Prompts[len(w.Prompts)-1]
+}
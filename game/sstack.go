@@ -10,6 +10,7 @@ import (
 )
 
 type SpriteStack struct {
+	sprite        string
 	layers        []*ebiten.Image
 	LayerDistance float64
 	Alpha         float32
@@ -35,6 +36,7 @@ func NewSpriteStack(sprite string) *SpriteStack {
 		panic(err)
 	}
 	ss.layers = layers
+	ss.sprite = sprite
 
 	return ss
 }
@@ -45,6 +47,12 @@ func (ss *SpriteStack) SetSprite(sprite string) {
 		panic(err)
 	}
 	ss.layers = layers
+	ss.sprite = sprite
+}
+
+// Sprite returns the name of the sprite the stack was loaded from.
+func (ss *SpriteStack) Sprite() string {
+	return ss.sprite
 }
 
 func (ss *SpriteStack) IsoGeoM(geom ebiten.GeoM) ebiten.GeoM {
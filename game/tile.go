@@ -13,6 +13,13 @@ type Tile struct {
 	Ticker      int
 	Glitchion   float64
 	Rotation    float64
+	Switches    []string // Flag conditions for the tile to be there at all, such as for bridges.
+	Off         bool     // Set when the tile's switches don't pass. Off tiles are neither drawn nor walkable.
+}
+
+// Blocks returns true if the tile can't be walked on, either by being a wall or by being switched off.
+func (t *Tile) Blocks() bool {
+	return t.BlocksMove || t.Off
 }
 
 func (t *Tile) Update() {
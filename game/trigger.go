@@ -0,0 +1,140 @@
+package game
+
+// TriggerFunc is called with the actor that caused the trigger to fire.
+type TriggerFunc func(w *World, r *Room, t *Trigger, a Actor)
+
+// Trigger is a rectangular area of a room that fires callbacks when actors enter it, leave it, or take a turn while inside it. A tile trigger is just a 1x1 area.
+type Trigger struct {
+	X, Y     int
+	W, H     int
+	Tag      string
+	Once     bool // Disable the trigger after it is first entered.
+	Filter   func(a Actor) bool
+	OnEnter  TriggerFunc
+	OnLeave  TriggerFunc
+	OnTurn   TriggerFunc
+	inside   []Actor
+	disabled bool
+}
+
+// TriggerPlayer is a Filter that only lets the player fire a trigger.
+func TriggerPlayer(a Actor) bool {
+	return a.Tag() == "player"
+}
+
+func NewTileTrigger(x, y int) *Trigger {
+	return &Trigger{X: x, Y: y, W: 1, H: 1}
+}
+
+func NewAreaTrigger(x, y, w, h int) *Trigger {
+	return &Trigger{X: x, Y: y, W: w, H: h}
+}
+
+func (t *Trigger) Contains(x, y int) bool {
+	w, h := t.W, t.H
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	return x >= t.X && x < t.X+w && y >= t.Y && y < t.Y+h
+}
+
+// Inside returns the actors currently within the trigger.
+func (t *Trigger) Inside() []Actor {
+	return t.inside
+}
+
+func (t *Trigger) Disable() {
+	t.disabled = true
+	t.inside = nil
+}
+
+func (t *Trigger) Enable() {
+	t.disabled = false
+}
+
+func (t *Trigger) Disabled() bool {
+	return t.disabled
+}
+
+func containsActor(actors []Actor, a Actor) bool {
+	for _, a2 := range actors {
+		if a2 == a {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Room) AddTrigger(t *Trigger) {
+	r.Triggers = append(r.Triggers, t)
+}
+
+func (r *Room) RemoveTrigger(t *Trigger) {
+	for i, t2 := range r.Triggers {
+		if t2 == t {
+			r.Triggers = append(r.Triggers[:i], r.Triggers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (r *Room) GetTriggerByTag(tag string) *Trigger {
+	for _, t := range r.Triggers {
+		if t.Tag == tag {
+			return t
+		}
+	}
+	return nil
+}
+
+// updateTriggers fires enter and leave callbacks based on the current actor positions.
+func (r *Room) updateTriggers(w *World) {
+	for _, t := range r.Triggers {
+		if t.disabled {
+			continue
+		}
+		var inside []Actor
+		for _, a := range r.Actors {
+			if t.Filter != nil && !t.Filter(a) {
+				continue
+			}
+			if x, y, _ := a.Position(); t.Contains(x, y) {
+				inside = append(inside, a)
+			}
+		}
+		prev := t.inside
+		t.inside = inside
+		for _, a := range prev {
+			if !containsActor(inside, a) && t.OnLeave != nil {
+				t.OnLeave(w, r, t, a)
+			}
+		}
+		for _, a := range inside {
+			if containsActor(prev, a) {
+				continue
+			}
+			if t.OnEnter != nil {
+				t.OnEnter(w, r, t, a)
+			}
+			if t.Once {
+				t.Disable()
+				break
+			}
+		}
+	}
+}
+
+// turnTriggers fires turn callbacks for every actor inside of a trigger.
+func (r *Room) turnTriggers(w *World) {
+	for _, t := range r.Triggers {
+		if t.disabled || t.OnTurn == nil {
+			continue
+		}
+		for _, a := range append([]Actor{}, t.inside...) {
+			t.OnTurn(w, r, t, a)
+		}
+	}
+}
@@ -19,49 +19,64 @@ type World struct {
 	LastRoom         *Room
 	Room             *Room
 	Camera           *Camera
-	RoutineChan      chan func() bool
-	RoutineChans     []func() bool
+	Clock            *Clock
+	Sequences        []*Sequence
 	Messages         []Message
 	Prompts          []*Prompt
 	Combat           *Combat
-	roomBuilder      func(string) *Room
+	Flags            map[string]bool
+	Visited          map[string]bool // Room IDs that have been entered.
+	Objectives       []*Objective
+	ShowObjectives   bool
+	Glitchdex        *Glitchdex
+	wantsGlitchdex   bool // Set when the GLITCHDEX button is clicked.
+	roomBuilder      func(name string, clock *Clock) (*Room, error)
 	Color            color.NRGBA
 	colorTicker      int
 	postProcessImage *ebiten.Image
 	SkipMessages     bool
+	focusX, focusY   int
+	focused          bool
+	minimapImage     *ebiten.Image
+	minimapPixels    []byte
+	walk             *Sequence                  // The player's click-to-walk, if any.
+	OnInput          func(*World, inputs.Input) // Called with every input before it is handled, such as for recording.
 }
 
-func NewWorld(roomBuilder func(string) *Room) *World {
+func NewWorld(roomBuilder func(name string, clock *Clock) (*Room, error)) *World {
 	return &World{
 		Camera:      NewCamera(),
-		RoutineChan: make(chan func() bool),
+		Clock:       NewClock(),
+		Flags:       make(map[string]bool),
+		Visited:     make(map[string]bool),
+		Glitchdex:   NewGlitchdex(),
 		roomBuilder: roomBuilder,
 	}
 }
 
+// Update simulates however many ticks the clock wants for this frame.
 func (w *World) Update() {
-	// Process routines.
-	for {
-		select {
-		case fnc := <-w.RoutineChan:
-			w.RoutineChans = append(w.RoutineChans, fnc)
-		default:
-			goto done
-		}
-	}
-done:
-	routines := w.RoutineChans[:0]
-	for _, r := range w.RoutineChans {
-		if !r() {
-			routines = append(routines, r)
-		}
+	for i := w.Clock.Ticks(); i > 0; i-- {
+		w.Tick()
 	}
-	w.RoutineChans = routines
+}
+
+// Tick advances the clock and simulates a single tick.
+func (w *World) Tick() {
+	w.Clock.advance()
+
+	// Process sequences.
+	w.updateSequences()
 
 	if len(w.Prompts) != 0 {
 		w.Prompts[len(w.Prompts)-1].Update()
 	}
 
+	// Keep the music down while big messages are up.
+	if len(w.Messages) > 0 {
+		res.Mixer.Duck(1)
+	}
+
 	if w.Combat != nil {
 		if c := w.Combat.Update(w, w.Room); c != nil {
 			if cmd, ok := c.(commands.CombatResult); ok {
@@ -74,6 +89,7 @@ done:
 						exp /= 2 // Half exp for capturing.
 						// TODO: Capture glitch.
 						w.PlayerActor.(CombatActor).AddGlitch(cmd.Loser.(GlitchActor))
+						w.Glitchdex.Capture(cmd.Loser.(Actor), w.Room)
 						w.Room.TileMessage(Message{
 							X:        px,
 							Y:        py,
@@ -91,6 +107,16 @@ done:
 						Color:    color.NRGBA{255, 255, 0, 255},
 						Duration: 3 * time.Second,
 					})
+					if holder, ok := w.PlayerActor.(ItemHolder); ok && cmd.CyclesGained > 0 {
+						holder.Inventory().Cycles += cmd.CyclesGained
+						w.Room.TileMessage(Message{
+							X:        px,
+							Y:        py,
+							Text:     fmt.Sprintf("+%d CYCLES", cmd.CyclesGained),
+							Color:    color.NRGBA{50, 200, 255, 255},
+							Duration: 3 * time.Second,
+						})
+					}
 					if lvl > 0 {
 						w.Room.TileMessage(Message{
 							X:        px,
@@ -102,9 +128,16 @@ done:
 					}
 					w.Room.RemoveActor(cmd.Loser.(Actor))
 					w.Room.UpdateGlitchion()
+					if !cmd.Destroyed {
+						w.objectiveEvent(ObjectiveCapture, cmd.Loser.(Actor).Name())
+					}
+					if tag := cmd.Loser.(Actor).Tag(); tag != "" {
+						w.objectiveEvent(ObjectiveDefeat, tag)
+					}
 					if w.Room.Glitches == 0 {
 						w.Room.Darkness = 0
 						res.PlaySound("cleansed")
+						w.objectiveEvent(ObjectiveCleanse, w.Room.ID)
 					}
 				} else if cmd.Loser == w.PlayerActor {
 					// Penalize the player in each stat by the level of the winner.
@@ -135,7 +168,10 @@ done:
 		}
 	}
 
-	if w.PlayerActor != nil {
+	if w.focused {
+		geom, _ := w.Room.GetTilePositionGeoM(w.focusX, w.focusY)
+		w.Camera.MoveTo(geom.Element(0, 2), geom.Element(1, 2))
+	} else if w.PlayerActor != nil {
 		x, y, _ := w.PlayerActor.Position()
 		geom, _ := w.Room.GetTilePositionGeoM(x, y)
 		w.Camera.MoveTo(geom.Element(0, 2), geom.Element(1, 2))
@@ -165,8 +201,16 @@ done:
 			switch cmd := cmd.(type) {
 			case commands.Prompt:
 				w.AddPrompt(cmd.Items, "", cmd.Handler, cmd.ShowVersions)
+			case commands.Dialogue:
+				w.StartDialogue(cmd.Dialogue.(*Dialogue))
+			case commands.Shop:
+				w.OpenShop(cmd.Shop.(*Shop))
 			case commands.Travel:
-				room := w.roomBuilder(cmd.Room)
+				room, err := w.GetRoom(cmd.Room)
+				if err != nil {
+					fmt.Println("couldn't travel:", err)
+					continue
+				}
 				var targetActor Actor
 				if cmd.Target != nil {
 					targetActor = cmd.Target.(Actor)
@@ -190,6 +234,11 @@ done:
 				defender := cmd.Defender.(CombatActor)
 				//w.Combat = NewCombat(384, 288, attacker, defender)
 				w.Combat = NewCombat(500, 388, attacker, defender)
+				for _, a := range []Actor{cmd.Attacker.(Actor), cmd.Defender.(Actor)} {
+					if a.Glitch() {
+						w.Glitchdex.Fight(a, w.Room)
+					}
+				}
 				res.Jukebox.Play("bad-health")
 			default:
 				fmt.Println("unhandled room->world command", cmd)
@@ -198,32 +247,61 @@ done:
 	}
 }
 
+// UseItem uses one of the player's items outside of combat and returns what happened. Like Input, it is passed to OnInput so it can be recorded.
+func (w *World) UseItem(t ItemType) (string, bool) {
+	if w.OnInput != nil {
+		w.OnInput(w, inputs.UseItem{Type: string(t)})
+	}
+	return w.useItem(t)
+}
+
+func (w *World) useItem(t ItemType) (string, bool) {
+	holder, ok := w.PlayerActor.(ItemHolder)
+	if !ok || !ItemUsableInField(t) || holder.Inventory().Count(t) == 0 {
+		return "", false
+	}
+	text, ok := UseItem(t, w.PlayerActor.(CombatActor))
+	if ok {
+		holder.Inventory().Remove(t)
+	}
+	return text, ok
+}
+
 func (w *World) Input(in inputs.Input) {
-	if len(w.Prompts) > 0 {
+	if w.OnInput != nil {
+		w.OnInput(w, in)
+	}
+	if item, ok := in.(inputs.UseItem); ok {
+		w.useItem(ItemType(item.Type))
+	} else if len(w.Prompts) > 0 {
 		w.Prompts[len(w.Prompts)-1].Input(in)
 	} else if w.Combat != nil {
 		w.Combat.Input(in)
+	} else if _, ok := in.(inputs.Cancel); ok && w.SkipSequences() {
+		// Cutscene skipped.
 	} else {
+		switch in.(type) {
+		case inputs.Direction, inputs.MapClick:
+			// Taking a step yourself stops any click-to-walk.
+			w.StopWalking()
+		}
 		if !w.Room.Input(w, in) {
 			switch in := in.(type) {
-			case inputs.Key:
-				// glitch 1-9 key selection.
-				for i := int(ebiten.KeyDigit1); i <= int(ebiten.KeyDigit9); i++ {
-					if in.Key != ebiten.Key(i) {
-						continue
-					}
-					if w.PlayerActor != nil {
-						glitches := w.PlayerActor.(CombatActor).Glitches()
-						j := i - int(ebiten.KeyDigit1)
-						if j < len(glitches) {
-							w.PlayerActor.(CombatActor).SetGlitch(glitches[j])
-						}
+			case inputs.ToggleObjectives:
+				w.ShowObjectives = !w.ShowObjectives
+			case inputs.SelectGlitch:
+				if w.PlayerActor != nil {
+					glitches := w.PlayerActor.(CombatActor).Glitches()
+					if in.Index < len(glitches) {
+						w.PlayerActor.(CombatActor).SetGlitch(glitches[in.Index])
 					}
 				}
 			case inputs.Click:
 				x, y := int(in.X), int(in.Y)
-				// Check for glitch select, absorb, etc.
-				if x >= glitchesUIX && x <= glitchesUIX+glitchesUIWidth && y >= glitchesUIY && y <= glitchesUIY+glitchesUIHeight {
+				// Check for minimap, glitch select, absorb, etc.
+				if tx, ty, ok := minimapTile(x, y); ok {
+					w.WalkPlayerTo(tx, ty)
+				} else if x >= glitchesUIX && x <= glitchesUIX+glitchesUIWidth && y >= glitchesUIY && y <= glitchesUIY+glitchesUIHeight {
 					gx := (x - glitchesUIX) / 16
 					if w.PlayerActor != nil {
 						glitches := w.PlayerActor.(CombatActor).Glitches()
@@ -258,6 +336,8 @@ func (w *World) Input(in inputs.Input) {
 							}, false)
 						}
 					}
+				} else if x >= glitchdexUIX && x <= glitchdexUIX+glitchdexUIWidth && y >= glitchdexUIY && y <= glitchdexUIY+glitchesUIHeight {
+					w.wantsGlitchdex = true
 				} else if x >= glitchesUIInfoX && x <= glitchesUIInfoX+glitchesUIInfoWidth && y >= glitchesUIY && y <= glitchesUIY+glitchesUIHeight {
 					if w.PlayerActor != nil {
 						glitch := w.PlayerActor.(CombatActor).CurrentGlitch()
@@ -328,10 +408,11 @@ func (w *World) Draw(screen *ebiten.Image) {
 	}
 	res.Text.Utils().RestoreState()
 
+	minimapUIScale = 0 // Set again if the minimap is drawn.
 	if w.Combat != nil {
 		geom := ebiten.GeoM{}
-		w.Combat.x = float64(screen.Bounds().Dx()/2) - float64(w.Combat.image.Bounds().Dx()/2)
-		w.Combat.y = float64(screen.Bounds().Dy()/2) - float64(w.Combat.image.Bounds().Dy()/2)
+		w.Combat.x = float64(screen.Bounds().Dx()/2) - float64(w.Combat.width/2)
+		w.Combat.y = float64(screen.Bounds().Dy()/2) - float64(w.Combat.height/2)
 		geom.Translate(w.Combat.x, w.Combat.y)
 		w.Combat.Draw(screen, geom)
 	} else if w.PlayerActor != nil {
@@ -411,6 +492,26 @@ func (w *World) Draw(screen *ebiten.Image) {
 			vector.StrokeRect(screen, float32(x), float32(y), float32(glitchesUIInfoWidth), glitchesUIHeight, 3, color.NRGBA{19, 19, 94, 255}, true)
 			res.Text.Draw(screen, "GLITCH INFO", x+glitchesUIInfoWidth/2, y+glitchesUIHeight/2+1)
 		}
+		// Draw a GLITCHDEX button, next to GLITCH INFO if it's there.
+		{
+			y := y - 1
+			x := x + 180 + 4
+			if len(glitches) > 0 {
+				x = glitchesUIInfoX + glitchesUIInfoWidth + paddingUI*2
+			}
+			res.Text.SetAlign(etxt.Center)
+			res.Text.SetColor(color.NRGBA{19, 19, 94, 200})
+			glitchdexUIX = x
+			glitchdexUIY = y
+			glitchdexUIWidth = res.Text.Measure("GLITCHDEX").IntWidth() + paddingUI*2
+			vector.DrawFilledRect(screen, float32(x), float32(y), float32(glitchdexUIWidth), glitchesUIHeight, color.NRGBA{194, 193, 174, 200}, false)
+			vector.StrokeRect(screen, float32(x), float32(y), float32(glitchdexUIWidth), glitchesUIHeight, 3, color.NRGBA{19, 19, 94, 255}, true)
+			res.Text.Draw(screen, "GLITCHDEX", x+glitchdexUIWidth/2, y+glitchesUIHeight/2+1)
+		}
+
+		if w.ShowObjectives {
+			w.drawObjectives(screen)
+		}
 
 		// Draw map UI
 		x = screen.Bounds().Dx() - int(mapUIWidth) - 6
@@ -420,8 +521,17 @@ func (w *World) Draw(screen *ebiten.Image) {
 		// bg is inverse of fg with wrap around.
 		bg := color.NRGBA{255 - fg.R, 255 - fg.G, 255 - fg.B, 255}
 
-		vector.DrawFilledRect(screen, float32(x), float32(y), mapUIWidth, mapUIHeight, bg, false)
-		vector.StrokeRect(screen, float32(x), float32(y), mapUIWidth, mapUIHeight, 3, fg, true)
+		mapHeight := mapUIHeight
+		minimapHeight := w.minimapHeight()
+		if minimapHeight > 0 {
+			mapHeight += minimapHeight + paddingUI
+		}
+
+		vector.DrawFilledRect(screen, float32(x), float32(y), mapUIWidth, float32(mapHeight), bg, false)
+		vector.StrokeRect(screen, float32(x), float32(y), mapUIWidth, float32(mapHeight), 3, fg, true)
+		if minimapHeight > 0 {
+			w.drawMinimap(screen, x+(mapUIWidth-w.minimapWidth())/2, y+mapUIHeight)
+		}
 		y += 3
 
 		res.Text.SetColor(fg)
@@ -456,53 +566,62 @@ func (w *World) Draw(screen *ebiten.Image) {
 	if len(w.Prompts) != 0 {
 		geom := ebiten.GeoM{}
 		prompt := w.Prompts[len(w.Prompts)-1]
-		prompt.x = float64(screen.Bounds().Dx()/2) - float64(prompt.image.Bounds().Dx()/2)
-		prompt.y = float64(screen.Bounds().Dy()/2) - float64(prompt.image.Bounds().Dy()/2)
+		prompt.x = float64(screen.Bounds().Dx()/2) - float64(prompt.width/2)
+		prompt.y = float64(screen.Bounds().Dy()/2) - float64(prompt.height/2)
 		geom.Translate(prompt.x, prompt.y)
 		prompt.Draw(screen, geom)
 	}
 }
 
+// GetRoom returns the named room, built to run off of the world's clock.
+func (w *World) GetRoom(name string) (*Room, error) {
+	return w.roomBuilder(name, w.Clock)
+}
+
 func (w *World) EnterRoom(room *Room) {
-	go func() {
-		<-w.FuncR(func() {
-			if w.Room != nil {
-				if w.Room.OnLeave != nil {
-					w.Room.OnLeave(w, w.Room)
-				}
-				w.Room.active = false
-			}
-		})
-		<-w.FuncR(func() {
-			w.LastRoom = w.Room
-			w.Room = room
-			if w.LastRoom != nil {
-				w.Room.DrawMode = w.LastRoom.DrawMode
-			}
-			if w.Room.Song != "" {
-				res.Jukebox.Play(w.Room.Song)
-			}
-			w.Room.UpdateGlitchion()
-			w.colorTicker = 0
-			if w.PlayerActor != nil {
-				x, y, _ := w.PlayerActor.Position()
-				geom, _ := w.Room.GetTilePositionGeoM(x, y)
-				w.Camera.SetPosition(geom.Element(0, 2), geom.Element(1, 2))
-			}
+	if w.Room != nil {
+		// Whoever traveled out is no longer within any of the room's triggers, so let them leave, such as stepping off of a plate.
+		w.Room.updateTriggers(w)
+		if w.Room.OnLeave != nil {
+			w.Room.OnLeave(w, w.Room)
+		}
+		w.Room.active = false
+		w.CancelSequences(w.Room)
+	}
+	w.LastRoom = w.Room
+	w.Room = room
+	if w.Room.ID != "" {
+		w.Visited[w.Room.ID] = true
+		w.objectiveEvent(ObjectiveReach, w.Room.ID)
+	}
+	if w.LastRoom != nil {
+		w.Room.DrawMode = w.LastRoom.DrawMode
+	}
+	if w.Room.Song != "" {
+		res.Jukebox.Play(w.Room.Song)
+	}
+	w.Room.UpdateGlitchion()
+	w.colorTicker = 0
+	if w.PlayerActor != nil {
+		x, y, _ := w.PlayerActor.Position()
+		geom, _ := w.Room.GetTilePositionGeoM(x, y)
+		w.Camera.SetPosition(geom.Element(0, 2), geom.Element(1, 2))
+	}
 
-		})
-		if w.Room.OnEnter != nil {
-			w.Room.OnEnter(w, w.Room)
+	count := len(w.Sequences)
+	if w.Room.OnEnter != nil {
+		w.Room.OnEnter(w, w.Room)
+	}
+	// Hold off on activating the room until whatever the room started on enter has played out.
+	entered := append([]*Sequence{}, w.Sequences[count:]...)
+	w.Play(room, WaitFor(entered...), Do(func(w *World) {
+		room.Activate()
+		if room.Glitches > 0 {
+			res.PlaySound("glitched")
+		} else {
+			res.PlaySound("cleansed")
 		}
-		<-w.FuncR(func() {
-			w.Room.Activate()
-			if w.Room.Glitches > 0 {
-				res.PlaySound("glitched")
-			} else {
-				res.PlaySound("cleansed")
-			}
-		})
-	}()
+	}))
 }
 
 func (w *World) AddPrompt(items []string, msg string, cb func(int, string) bool, showExtra bool) {
@@ -523,63 +642,6 @@ func (w *World) AddPrompt(items []string, msg string, cb func(int, string) bool,
 	}, showExtra))
 }
 
-func (w *World) MessageR(msg Message) chan bool {
-	done := make(chan bool)
-
-	msg.start = time.Now()
-	msg.id = messageID
-	if msg.Color.A == 0 {
-		msg.Color = color.NRGBA{0, 0, 0, 255}
-	}
-	if msg.Font == nil {
-		msg.Font = &res.DefFont
-	}
-	messageID++
-
-	first := true
-	fnc := func() bool {
-		if first {
-			w.Messages = append(w.Messages, msg)
-			if w.SkipMessages {
-				msg.Duration = 0
-			}
-			first = false
-		}
-
-		delta := time.Since(msg.start)
-		if delta < 200*time.Millisecond {
-			w.Messages[0].Color.A = uint8(float64(delta) / float64(200*time.Millisecond) * 200)
-			w.Messages[0].Background.A = uint8(float64(delta) / float64(200*time.Millisecond) * 200)
-			w.Messages[0].H = float64(delta) / float64(200*time.Millisecond)
-		} else if delta > msg.Duration-200*time.Millisecond {
-			w.Messages[0].Color.A = uint8(float64(msg.Duration-delta) / float64(200*time.Millisecond) * 200)
-			w.Messages[0].Background.A = uint8(float64(msg.Duration-delta) / float64(200*time.Millisecond) * 200)
-			w.Messages[0].H = float64(msg.Duration-delta) / float64(200*time.Millisecond)
-		}
-
-		if time.Since(msg.start) >= msg.Duration {
-			w.Messages = w.Messages[1:]
-			done <- true
-			return true
-		}
-		return false
-	}
-
-	w.RoutineChan <- fnc
-	return done
-}
-
-func (w *World) FuncR(fnc func()) chan bool {
-	done := make(chan bool)
-	f := func() bool {
-		fnc()
-		done <- true
-		return true
-	}
-	w.RoutineChan <- f
-	return done
-}
-
 const playerUIHeight = 84
 const playerUIWidth = 180
 const playerUIPadding = 4 // maybe?
@@ -595,6 +657,9 @@ var glitchesUIAbsorbX = 0
 var glitchesUIAbsorbWidth = 0
 var glitchesUIInfoX = 0
 var glitchesUIInfoWidth = 0
+var glitchdexUIX = 0
+var glitchdexUIY = 0
+var glitchdexUIWidth = 0
 
 const mapUIHeight = 43
 const mapUIWidth = 150
@@ -0,0 +1,35 @@
+//go:build headless
+
+package game_test
+
+import (
+	"testing"
+
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/inputs"
+	"github.com/kettek/ebihack23/rooms"
+)
+
+// TestTicks runs a world without a screen for a while, pacing around spawn, to make sure nothing in a tick needs one.
+func TestTicks(t *testing.T) {
+	w := game.NewWorld(rooms.GetRoom)
+	r, err := w.GetRoom("000_spawn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.EnterRoom(r)
+
+	steps := []inputs.Direction{{X: -1}, {X: 1}, {X: 1}, {X: -1}}
+	for i := 0; i < 5000; i++ {
+		if i%30 == 0 {
+			w.Input(steps[i/30%len(steps)])
+		}
+		w.Tick()
+	}
+	if w.Clock.Now() != 5000 {
+		t.Fatalf("clock is at %d, want 5000", w.Clock.Now())
+	}
+	if w.PlayerActor == nil {
+		t.Fatal("spawn never set the player")
+	}
+}
@@ -0,0 +1,173 @@
+package inputs
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/kettek/ebihack23/settings"
+)
+
+// stickThreshold is how far a gamepad's left stick must be pushed to count as a direction.
+const stickThreshold = 0.5
+
+// Mapper turns the keys, mouse buttons and gamepad buttons bound in settings into actions.
+type Mapper struct {
+	held     map[settings.Action]int // Ticks each action has been held for.
+	released map[settings.Action]bool
+	gamepads []ebiten.GamepadID
+}
+
+// Actions is the mapper everything reads actions from. It is updated once a tick, before the current state.
+var Actions = NewMapper()
+
+func NewMapper() *Mapper {
+	return &Mapper{
+		held:     make(map[settings.Action]int),
+		released: make(map[settings.Action]bool),
+	}
+}
+
+// Update reads the bound inputs for this tick.
+func (m *Mapper) Update() {
+	m.gamepads = m.gamepads[:0]
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			m.gamepads = append(m.gamepads, id)
+		}
+	}
+	for _, a := range settings.Actions {
+		m.released[a] = false
+		if m.down(a) {
+			m.held[a]++
+		} else {
+			if m.held[a] > 0 {
+				m.released[a] = true
+			}
+			m.held[a] = 0
+		}
+	}
+}
+
+func (m *Mapper) down(a settings.Action) bool {
+	for _, b := range settings.Bindings[a] {
+		switch {
+		case b.Key != nil:
+			if ebiten.IsKeyPressed(*b.Key) {
+				return true
+			}
+		case b.Mouse != nil:
+			if ebiten.IsMouseButtonPressed(*b.Mouse) {
+				return true
+			}
+		case b.Pad != nil:
+			for _, id := range m.gamepads {
+				if ebiten.IsStandardGamepadButtonPressed(id, *b.Pad) {
+					return true
+				}
+			}
+		}
+	}
+	// Directions also follow the left stick.
+	for _, id := range m.gamepads {
+		x := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+		y := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+		switch {
+		case a == settings.ActionUp && y < -stickThreshold,
+			a == settings.ActionDown && y > stickThreshold,
+			a == settings.ActionLeft && x < -stickThreshold,
+			a == settings.ActionRight && x > stickThreshold:
+			return true
+		}
+	}
+	return false
+}
+
+// Pressed returns true while the action is held.
+func (m *Mapper) Pressed(a settings.Action) bool {
+	return m.held[a] > 0
+}
+
+// JustPressed returns true on the tick the action was pressed.
+func (m *Mapper) JustPressed(a settings.Action) bool {
+	return m.held[a] == 1
+}
+
+// JustReleased returns true on the tick the action was let go.
+func (m *Mapper) JustReleased(a settings.Action) bool {
+	return m.released[a]
+}
+
+// Repeated returns true when the action is pressed, and then every so often while it is held.
+func (m *Mapper) Repeated(a settings.Action) bool {
+	h := m.held[a]
+	if h == 1 {
+		return true
+	}
+	if h <= settings.KeyRepeatDelay || settings.KeyRepeatInterval <= 0 {
+		return false
+	}
+	return (h-settings.KeyRepeatDelay)%settings.KeyRepeatInterval == 0
+}
+
+// Direction returns the direction held this tick, repeating while held.
+func (m *Mapper) Direction() (x, y int) {
+	if m.Repeated(settings.ActionUp) {
+		y--
+	}
+	if m.Repeated(settings.ActionDown) {
+		y++
+	}
+	if m.Repeated(settings.ActionLeft) {
+		x--
+	}
+	if m.Repeated(settings.ActionRight) {
+		x++
+	}
+	return x, y
+}
+
+// Inputs returns this tick's actions as inputs. Clicks are at the cursor.
+func (m *Mapper) Inputs() (ins []Input) {
+	mod := m.Pressed(settings.ActionModifier)
+	if m.JustReleased(settings.ActionCancel) {
+		ins = append(ins, Cancel{})
+	}
+	if m.JustReleased(settings.ActionConfirm) {
+		ins = append(ins, Confirm{})
+	}
+	if x, y := m.Direction(); x != 0 || y != 0 {
+		ins = append(ins, Direction{X: x, Y: y, Mod: mod})
+	}
+	if m.JustReleased(settings.ActionObjectives) {
+		ins = append(ins, ToggleObjectives{})
+	}
+	for i, a := range settings.GlitchActions {
+		if m.JustReleased(a) {
+			ins = append(ins, SelectGlitch{Index: i})
+		}
+	}
+	cx, cy := ebiten.CursorPosition()
+	if m.JustReleased(settings.ActionClick) {
+		ins = append(ins, Click{X: float64(cx), Y: float64(cy), Which: ebiten.MouseButtonLeft, Mod: mod})
+	} else if m.JustReleased(settings.ActionAltClick) {
+		ins = append(ins, Click{X: float64(cx), Y: float64(cy), Which: ebiten.MouseButtonRight, Mod: mod})
+	}
+	return ins
+}
+
+// Capture returns the first key, mouse button or gamepad button let go this tick, for binding it to an action.
+func (m *Mapper) Capture() (settings.Binding, bool) {
+	if keys := inpututil.AppendJustReleasedKeys(nil); len(keys) > 0 {
+		return settings.KeyBinding(keys[0]), true
+	}
+	for b := ebiten.MouseButton0; b <= ebiten.MouseButtonMax; b++ {
+		if inpututil.IsMouseButtonJustReleased(b) {
+			return settings.MouseBinding(b), true
+		}
+	}
+	for _, id := range m.gamepads {
+		if buttons := inpututil.AppendJustReleasedStandardGamepadButtons(id, nil); len(buttons) > 0 {
+			return settings.PadBinding(buttons[0]), true
+		}
+	}
+	return settings.Binding{}, false
+}
@@ -25,6 +25,14 @@ type MapClick struct {
 	Mod   bool
 }
 
-type Key struct {
-	Key ebiten.Key
+type ToggleObjectives struct{}
+
+// UseItem uses one of the player's items outside of combat.
+type UseItem struct {
+	Type string
+}
+
+// SelectGlitch picks the glitch at Index in the party.
+type SelectGlitch struct {
+	Index int
 }
@@ -0,0 +1,145 @@
+package replay
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kettek/ebihack23/game"
+)
+
+// Player feeds a recording's inputs back into a world, a tick at a time.
+type Player struct {
+	rec  *Recording
+	next int // Index of the next tick of inputs to give.
+}
+
+func NewPlayer(rec *Recording) *Player {
+	return &Player{
+		rec: rec,
+	}
+}
+
+// World makes the fresh world the recording is played back into.
+func (p *Player) World() (*game.World, error) {
+	p.next = 0
+	return NewWorld(p.rec.Seed, p.rec.Room)
+}
+
+// Recording returns the recording being played.
+func (p *Player) Recording() *Recording {
+	return p.rec
+}
+
+// Done returns true once the world has reached the tick the recording ended on.
+func (p *Player) Done(w *game.World) bool {
+	return w.Clock.Now() >= p.rec.End
+}
+
+// Step gives the world whatever inputs were given on its current tick, then simulates it.
+func (p *Player) Step(w *game.World) {
+	now := w.Clock.Now()
+	for p.next < len(p.rec.Ticks) && p.rec.Ticks[p.next].Tick <= now {
+		if p.rec.Ticks[p.next].Tick == now {
+			for _, i := range p.rec.Ticks[p.next].Inputs {
+				if in := i.decode(); in != nil {
+					w.Input(in)
+				}
+			}
+		}
+		p.next++
+	}
+	w.Tick()
+}
+
+// Update is used in place of World.Update while playing back. It steps as many ticks as the clock wants, stopping once the recording is done.
+func (p *Player) Update(w *game.World) {
+	for i := w.Clock.Ticks(); i > 0 && !p.Done(w); i-- {
+		p.Step(w)
+	}
+}
+
+// Play replays the whole recording into a fresh world without drawing anything, and returns the world as it was left.
+func Play(rec *Recording) (*game.World, error) {
+	p := NewPlayer(rec)
+	w, err := p.World()
+	if err != nil {
+		return nil, err
+	}
+	for !p.Done(w) {
+		p.Step(w)
+	}
+	return w, nil
+}
+
+// Result is the state of a world that a replay is checked against.
+type Result struct {
+	Room     string `json:"room"`
+	X        int    `json:"x"`
+	Y        int    `json:"y"`
+	Level    int    `json:"level"`
+	Stats    [3]int `json:"stats"`    // Current penetration, firewall and integrity.
+	Glitches int    `json:"glitches"` // Glitches left in the room.
+	Party    int    `json:"party"`    // Glitches the player has captured.
+}
+
+// Summarize returns the state of the world to check a replay against.
+func Summarize(w *game.World) (r Result) {
+	if w.Room != nil {
+		r.Room = w.Room.ID
+		r.Glitches = w.Room.Glitches
+	}
+	if w.PlayerActor != nil {
+		r.X, r.Y, _ = w.PlayerActor.Position()
+		if c, ok := w.PlayerActor.(game.CombatActor); ok {
+			r.Level = c.Level()
+			r.Stats[0], r.Stats[1], r.Stats[2] = c.CurrentStats()
+			r.Party = len(c.Glitches())
+		}
+	}
+	return r
+}
+
+// Check returns an error listing everything about the world that differs from want.
+func Check(w *game.World, want Result) error {
+	got := Summarize(w)
+	var diffs []string
+	if got.Room != want.Room {
+		diffs = append(diffs, fmt.Sprintf("room is %q, want %q", got.Room, want.Room))
+	}
+	if got.X != want.X || got.Y != want.Y {
+		diffs = append(diffs, fmt.Sprintf("player is at %d,%d, want %d,%d", got.X, got.Y, want.X, want.Y))
+	}
+	if got.Level != want.Level {
+		diffs = append(diffs, fmt.Sprintf("level is %d, want %d", got.Level, want.Level))
+	}
+	if got.Stats != want.Stats {
+		diffs = append(diffs, fmt.Sprintf("stats are %v, want %v", got.Stats, want.Stats))
+	}
+	if got.Glitches != want.Glitches {
+		diffs = append(diffs, fmt.Sprintf("%d glitches left in the room, want %d", got.Glitches, want.Glitches))
+	}
+	if got.Party != want.Party {
+		diffs = append(diffs, fmt.Sprintf("%d glitches in the party, want %d", got.Party, want.Party))
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("replay diverged: %s", strings.Join(diffs, "; "))
+	}
+	return nil
+}
+
+// Verify replays a recording headlessly and checks that it ends up in the state it was recorded in. It is meant for tests, built with `-tags headless` so that no audio device is needed:
+//
+//	rec, _ := replay.Load("testdata/hall.json")
+//	if err := replay.Verify(rec); err != nil {
+//		t.Fatal(err)
+//	}
+func Verify(rec *Recording) error {
+	if rec.Result == nil {
+		return fmt.Errorf("recording has no result to check against")
+	}
+	w, err := Play(rec)
+	if err != nil {
+		return err
+	}
+	return Check(w, *rec.Result)
+}
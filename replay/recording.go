@@ -0,0 +1,173 @@
+// Package replay records the inputs given to a world and plays them back into a fresh one. Since the world runs off of its own clock and a seeded random number generator, a replay ends up exactly where the recording did.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/inputs"
+	"github.com/kettek/ebihack23/rooms"
+)
+
+// Recording is a recorded session: how the world was made, the inputs given on each tick, and what state it ended in.
+type Recording struct {
+	Seed   int64   `json:"seed"`
+	Room   string  `json:"room"`
+	Ticks  []Tick  `json:"ticks"`
+	End    int64   `json:"end"`    // Clock tick the recording stopped on.
+	Result *Result `json:"result"` // State of the world when it stopped.
+}
+
+// Tick is every input given on a single clock tick.
+type Tick struct {
+	Tick   int64   `json:"tick"`
+	Inputs []Input `json:"inputs"`
+}
+
+// Input is an inputs.Input that can be saved.
+type Input struct {
+	Type  string  `json:"type"` // direction, confirm, cancel, click, mapClick, objectives, glitch or item
+	X     float64 `json:"x,omitempty"`
+	Y     float64 `json:"y,omitempty"`
+	Mod   bool    `json:"mod,omitempty"`
+	Which int     `json:"which,omitempty"` // Mouse button of a click, or the party index of a glitch.
+	Item  string  `json:"item,omitempty"`
+}
+
+func encode(in inputs.Input) (Input, bool) {
+	switch in := in.(type) {
+	case inputs.Direction:
+		return Input{Type: "direction", X: float64(in.X), Y: float64(in.Y), Mod: in.Mod}, true
+	case inputs.Confirm:
+		return Input{Type: "confirm"}, true
+	case inputs.Cancel:
+		return Input{Type: "cancel"}, true
+	case inputs.Click:
+		return Input{Type: "click", X: in.X, Y: in.Y, Mod: in.Mod, Which: int(in.Which)}, true
+	case inputs.MapClick:
+		return Input{Type: "mapClick", X: float64(in.X), Y: float64(in.Y), Mod: in.Mod, Which: int(in.Which)}, true
+	case inputs.ToggleObjectives:
+		return Input{Type: "objectives"}, true
+	case inputs.SelectGlitch:
+		return Input{Type: "glitch", Which: in.Index}, true
+	case inputs.UseItem:
+		return Input{Type: "item", Item: in.Type}, true
+	}
+	return Input{}, false
+}
+
+func (i Input) decode() inputs.Input {
+	switch i.Type {
+	case "direction":
+		return inputs.Direction{X: int(i.X), Y: int(i.Y), Mod: i.Mod}
+	case "confirm":
+		return inputs.Confirm{}
+	case "cancel":
+		return inputs.Cancel{}
+	case "click":
+		return inputs.Click{X: i.X, Y: i.Y, Mod: i.Mod, Which: ebiten.MouseButton(i.Which)}
+	case "mapClick":
+		return inputs.MapClick{X: int(i.X), Y: int(i.Y), Mod: i.Mod, Which: ebiten.MouseButton(i.Which)}
+	case "objectives":
+		return inputs.ToggleObjectives{}
+	case "glitch":
+		return inputs.SelectGlitch{Index: i.Which}
+	case "item":
+		return inputs.UseItem{Type: i.Item}
+	}
+	return nil
+}
+
+// Load reads a recording from a file.
+func Load(path string) (*Recording, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rec := &Recording{}
+	if err := json.Unmarshal(b, rec); err != nil {
+		return nil, fmt.Errorf("recording %s: %w", path, err)
+	}
+	return rec, nil
+}
+
+// Save writes the recording to a file.
+func (rec *Recording) Save(path string) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// NewWorld seeds the random number generator and makes a fresh world in the given room. Recording and playback both start this way so that they start out the same.
+func NewWorld(seed int64, room string) (*game.World, error) {
+	rand.Seed(seed)
+	rooms.ClearCache()
+	w := game.NewWorld(rooms.GetRoom)
+	r, err := w.GetRoom(room)
+	if err != nil {
+		return nil, err
+	}
+	w.EnterRoom(r)
+	return w, nil
+}
+
+// Recorder records every input given to a world.
+type Recorder struct {
+	rec   Recording
+	world *game.World
+}
+
+// NewRecorder makes a fresh world with the given seed and room and starts recording its inputs.
+func NewRecorder(seed int64, room string) (*Recorder, error) {
+	w, err := NewWorld(seed, room)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{
+		rec: Recording{
+			Seed: seed,
+			Room: room,
+		},
+		world: w,
+	}
+	r.world.OnInput = r.record
+	return r, nil
+}
+
+// World returns the world being recorded.
+func (r *Recorder) World() *game.World {
+	return r.world
+}
+
+func (r *Recorder) record(w *game.World, in inputs.Input) {
+	i, ok := encode(in)
+	if !ok {
+		return
+	}
+	now := w.Clock.Now()
+	if len(r.rec.Ticks) == 0 || r.rec.Ticks[len(r.rec.Ticks)-1].Tick != now {
+		r.rec.Ticks = append(r.rec.Ticks, Tick{Tick: now})
+	}
+	t := &r.rec.Ticks[len(r.rec.Ticks)-1]
+	t.Inputs = append(t.Inputs, i)
+}
+
+// Recording returns what has been recorded so far, ending on the current tick.
+func (r *Recorder) Recording() *Recording {
+	rec := r.rec
+	rec.End = r.world.Clock.Now()
+	result := Summarize(r.world)
+	rec.Result = &result
+	return &rec
+}
+
+// Save writes what has been recorded so far to a file.
+func (r *Recorder) Save(path string) error {
+	return r.Recording().Save(path)
+}
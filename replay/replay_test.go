@@ -0,0 +1,68 @@
+//go:build headless
+
+package replay
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/kettek/ebihack23/inputs"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/hall.json by recording hallScript")
+
+// hallScript walks from spawn to its terminal, unlocks the door, then heads through it to the hall. It waits out spawn's intro first, since the room doesn't take turns until then.
+var hallScript = map[int64][]inputs.Input{
+	700: {inputs.Direction{Y: -1}},
+	730: {inputs.Direction{X: 1}},
+	760: {inputs.Direction{Y: -1}}, // Bump the terminal.
+	790: {inputs.Direction{Y: 1}},  // Manage Safeguard
+	800: {inputs.Confirm{}},
+	810: {inputs.Direction{Y: 1}}, // Unlock
+	820: {inputs.Confirm{}},
+	830: {inputs.Cancel{}},
+	860: {inputs.Direction{X: -1}},
+	890: {inputs.Direction{Y: -1}}, // Through the door.
+}
+
+const hallEnd = 950
+
+func recordHall(t *testing.T) *Recording {
+	r, err := NewRecorder(1, "000_spawn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := r.World()
+	for w.Clock.Now() < hallEnd {
+		for _, in := range hallScript[w.Clock.Now()] {
+			w.Input(in)
+		}
+		w.Tick()
+	}
+	return r.Recording()
+}
+
+func TestRecordThenVerify(t *testing.T) {
+	rec := recordHall(t)
+	if rec.Result.Room != "000a_hall" {
+		t.Fatalf("script ended in %q, want 000a_hall", rec.Result.Room)
+	}
+	if err := Verify(rec); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyHall(t *testing.T) {
+	if *update {
+		if err := recordHall(t).Save("testdata/hall.json"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rec, err := Load("testdata/hall.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(rec); err != nil {
+		t.Fatal(err)
+	}
+}
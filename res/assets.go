@@ -5,6 +5,7 @@ import (
 	"embed"
 	"image"
 	_ "image/png"
+	"io/fs"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -13,6 +14,8 @@ import (
 //go:embed *.ttf
 //go:embed *.wav
 //go:embed *.ogg
+//go:embed dialogues/*.json
+//go:embed rooms/*.json rooms/*.tmx
 var FS embed.FS
 
 var loadedSpriteStacks = make(map[string][]*ebiten.Image)
@@ -67,3 +70,9 @@ func LoadImage(s string) *ebiten.Image {
 	images[s] = ebiten.NewImageFromImage(img)
 	return images[s]
 }
+
+// HasSprite returns whether the given sprite exists. Missing sprites otherwise silently fall back to missing.png.
+func HasSprite(sprite string) bool {
+	_, err := fs.Stat(FS, sprite+".png")
+	return err == nil
+}
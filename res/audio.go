@@ -1,16 +1,21 @@
 package res
 
 import (
-	"bytes"
-	"io"
+	"io/fs"
 	"strings"
-
-	"github.com/hajimehoshi/ebiten/v2/audio"
-	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
-	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+	"time"
 )
 
-var audioContext *audio.Context
+// audioPlayer is what songs and sounds are played through. Normally it's an ebiten audio.Player, but headless builds swap it for one that plays nothing.
+type audioPlayer interface {
+	Play()
+	Pause()
+	IsPlaying() bool
+	Rewind() error
+	SetPosition(time.Duration) error
+	SetVolume(float64)
+}
+
 var Jukebox = &jukebox{}
 
 type jukebox struct {
@@ -21,43 +26,41 @@ type jukebox struct {
 
 type song struct {
 	name   string
-	player *audio.Player
+	player audioPlayer
 }
 
 func newSong(name string) *song {
 	s := &song{
-		name: name,
+		name:   name,
+		player: newSongPlayer(name),
 	}
-	sp, err := audioContext.NewPlayer(GetSoundStream(name))
-	if err != nil {
-		panic(err)
-	}
-	s.player = sp
 	s.player.SetVolume(0)
 	s.player.Play()
 	return s
 }
 
 func (j *jukebox) Update() {
+	Mixer.update()
+	volume := Mixer.Volume(BusMusic)
 	if j.fade > 0 {
 		j.fade--
 		if j.lastSong != nil {
-			j.lastSong.player.SetVolume(float64(j.fade) / 100)
+			j.lastSong.player.SetVolume(volume * float64(j.fade) / 100)
 			if j.fade == 0 {
 				j.lastSong.player.SetVolume(0)
 				j.lastSong.player.Pause()
 				j.lastSong = nil
 			}
 		}
-		if j.song != nil {
-			j.song.player.SetVolume(float64(100-j.fade) / 100)
-		}
 	}
-	if j.song != nil && !j.song.player.IsPlaying() {
-		if err := j.song.player.Rewind(); err != nil {
-			panic(err)
+	if j.song != nil {
+		j.song.player.SetVolume(volume * float64(100-j.fade) / 100)
+		if !j.song.player.IsPlaying() {
+			if err := j.song.player.Rewind(); err != nil {
+				panic(err)
+			}
+			j.song.player.Play()
 		}
-		j.song.player.Play()
 	}
 }
 
@@ -77,22 +80,8 @@ func (j *jukebox) Play(name string) {
 	}
 }
 
-var SoundStreams = map[string]*vorbis.Stream{}
-
-func GetSoundStream(name string) *vorbis.Stream {
-	file, err := FS.Open(name + ".ogg")
-	if err != nil {
-		panic(err)
-	}
-	s, err := vorbis.DecodeWithSampleRate(audioContext.SampleRate(), file)
-	if err != nil {
-		panic(err)
-	}
-	return s
-}
-
 type SoundPlayer struct {
-	*audio.Player
+	audioPlayer
 	Looping bool
 	Next    *SoundPlayer
 }
@@ -102,11 +91,12 @@ type SoundEffect struct {
 }
 
 func (s SoundEffect) Play() *SoundPlayer {
-	p := audioContext.NewPlayerFromBytes(s.bytes)
+	p := newSoundPlayer(s.bytes)
+	p.SetVolume(Mixer.Volume(BusSound))
 	p.Play()
 	sp := &SoundPlayer{
-		Player:  p,
-		Looping: false,
+		audioPlayer: p,
+		Looping:     false,
 	}
 	PlayingSounds = append(PlayingSounds, sp)
 	return sp
@@ -125,10 +115,11 @@ func GetSound(name string) *SoundPlayer {
 	if _, ok := Sounds[name]; !ok {
 		panic("sound not found")
 	}
-	p := audioContext.NewPlayerFromBytes(Sounds[name].bytes)
+	p := newSoundPlayer(Sounds[name].bytes)
+	p.SetVolume(Mixer.Volume(BusSound))
 	sp := &SoundPlayer{
-		Player:  p,
-		Looping: false,
+		audioPlayer: p,
+		Looping:     false,
 	}
 	return sp
 }
@@ -148,8 +139,10 @@ func PlayLoopedSound(name string) *SoundPlayer {
 }
 
 func UpdateSounds() {
+	volume := Mixer.Volume(BusSound)
 	sounds := PlayingSounds[:0]
 	for _, s := range PlayingSounds {
+		s.SetVolume(volume)
 		if s.IsPlaying() {
 			sounds = append(sounds, s)
 			continue
@@ -159,6 +152,7 @@ func UpdateSounds() {
 				s.Play()
 				sounds = append(sounds, s)
 			} else if s.Next != nil {
+				s.Next.SetVolume(volume)
 				s.Next.Play()
 				sounds = append(sounds, s.Next)
 			}
@@ -168,8 +162,6 @@ func UpdateSounds() {
 }
 
 func init() {
-	audioContext = audio.NewContext(48000)
-
 	files, err := FS.ReadDir(".")
 	if err != nil {
 		panic(err)
@@ -183,11 +175,7 @@ func init() {
 			if err != nil {
 				panic(err)
 			}
-			s, err := wav.DecodeWithSampleRate(audioContext.SampleRate(), bytes.NewReader(b))
-			if err != nil {
-				panic(err)
-			}
-			wavBytes, err := io.ReadAll(s)
+			wavBytes, err := decodeSound(b)
 			if err != nil {
 				panic(err)
 			}
@@ -196,3 +184,15 @@ func init() {
 		}
 	}
 }
+
+// HasSound returns whether the given sound effect exists.
+func HasSound(name string) bool {
+	_, ok := Sounds[name]
+	return ok
+}
+
+// HasSong returns whether the given song exists.
+func HasSong(name string) bool {
+	_, err := fs.Stat(FS, name+".ogg")
+	return err == nil
+}
@@ -0,0 +1,49 @@
+//go:build !headless
+
+package res
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+)
+
+var audioContext = audio.NewContext(48000)
+
+var SoundStreams = map[string]*vorbis.Stream{}
+
+func GetSoundStream(name string) *vorbis.Stream {
+	file, err := FS.Open(name + ".ogg")
+	if err != nil {
+		panic(err)
+	}
+	s, err := vorbis.DecodeWithSampleRate(audioContext.SampleRate(), file)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func newSongPlayer(name string) audioPlayer {
+	p, err := audioContext.NewPlayer(GetSoundStream(name))
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func newSoundPlayer(b []byte) audioPlayer {
+	return audioContext.NewPlayerFromBytes(b)
+}
+
+// decodeSound decodes a WAV file into the raw samples the audio context plays.
+func decodeSound(b []byte) ([]byte, error) {
+	s, err := wav.DecodeWithSampleRate(audioContext.SampleRate(), bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(s)
+}
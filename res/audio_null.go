@@ -0,0 +1,28 @@
+//go:build headless
+
+package res
+
+import "time"
+
+// nullPlayer is the audio player for headless builds. There's no audio context at all, so nothing is decoded and nothing ever plays.
+type nullPlayer struct{}
+
+func (nullPlayer) Play()                           {}
+func (nullPlayer) Pause()                          {}
+func (nullPlayer) IsPlaying() bool                 { return false }
+func (nullPlayer) Rewind() error                   { return nil }
+func (nullPlayer) SetPosition(time.Duration) error { return nil }
+func (nullPlayer) SetVolume(float64)               {}
+
+func newSongPlayer(name string) audioPlayer {
+	return nullPlayer{}
+}
+
+func newSoundPlayer(b []byte) audioPlayer {
+	return nullPlayer{}
+}
+
+// decodeSound skips decoding, as there's nothing to play it on.
+func decodeSound(b []byte) ([]byte, error) {
+	return nil, nil
+}
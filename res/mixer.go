@@ -0,0 +1,67 @@
+package res
+
+import (
+	"math"
+
+	"github.com/kettek/ebihack23/settings"
+)
+
+// Bus is a group of audio whose volume is set together.
+type Bus int
+
+const (
+	BusMusic Bus = iota
+	BusSound
+)
+
+// duckSpeed is how much the music's ducking changes each update, so it eases in and out rather than jumping.
+const duckSpeed = 0.05
+
+// Mixer sets the volume of everything played from the volumes and mutes in settings.
+var Mixer = &mixer{}
+
+type mixer struct {
+	ducked int     // Updates left to keep music ducked for.
+	duck   float64 // How ducked music currently is, from 0 to 1.
+}
+
+// Duck turns music down for at least the given number of updates.
+func (m *mixer) Duck(updates int) {
+	if updates > m.ducked {
+		m.ducked = updates
+	}
+}
+
+// Volume returns the volume the bus should play at right now.
+func (m *mixer) Volume(b Bus) float64 {
+	if settings.Muted {
+		return 0
+	}
+	v := clampVolume(settings.MasterVolume)
+	switch b {
+	case BusMusic:
+		if settings.MusicMuted {
+			return 0
+		}
+		v *= clampVolume(settings.MusicVolume) * (1 - m.duck*clampVolume(settings.MusicDucking))
+	case BusSound:
+		if settings.SoundMuted {
+			return 0
+		}
+		v *= clampVolume(settings.SoundVolume)
+	}
+	return v
+}
+
+func (m *mixer) update() {
+	if m.ducked > 0 {
+		m.ducked--
+		m.duck = math.Min(1, m.duck+duckSpeed)
+	} else {
+		m.duck = math.Max(0, m.duck-duckSpeed)
+	}
+}
+
+func clampVolume(v float64) float64 {
+	return math.Min(1, math.Max(0, v))
+}
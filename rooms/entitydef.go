@@ -1,13 +1,339 @@
 package rooms
 
 import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+
 	"github.com/kettek/ebihack23/actors"
+	"github.com/kettek/ebihack23/commands"
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/res"
 )
 
+// EntityDef describes an entity that can be placed in a room. Anything that can't be described with data is left to the named entity script.
 type EntityDef struct {
-	Actor      string
-	OnCreate   actors.CreateFunc
-	OnInteract actors.InteractFunc
+	Actor      string           `json:"actor"`
+	Species    string           `json:"species"` // Glitch species to start from. Anything else in the def overrides it.
+	Name       string           `json:"name"`
+	Tag        string           `json:"tag"`
+	Sprite     string           `json:"sprite"`
+	Rotation   float64          `json:"rotation"`
+	Properties EntityProperties `json:"properties"`
+	Link       *Link            `json:"link"`     // Travel destination for doors.
+	Door       *DoorDef         `json:"door"`     // Lock state and sprites for doors.
+	Shop       *ShopDef         `json:"shop"`     // Stock for vendor terminals.
+	Dialogue   string           `json:"dialogue"` // Dialogue an npc runs when talked to.
+	Lines      []LineDef        `json:"lines"`    // What an npc without a dialogue says.
+	Switch     []string         `json:"switch"`   // Flag conditions a gate opens on, or the flag a switch flips.
+	Script     string           `json:"script"`   // Name of an entity script to attach.
+}
+
+// LineDef is a line an npc says if all of its story flag conditions pass.
+type LineDef struct {
+	If   []string `json:"if"`
+	Text string   `json:"text"`
 }
 
 type EntityDefs map[string]EntityDef
+
+// EntityProperties are optional properties applied to an entity after it is created. Unset properties leave the actor's defaults alone.
+type EntityProperties struct {
+	Z       *int        `json:"z"`
+	Floats  *bool       `json:"floats"`
+	Wanders *bool       `json:"wanders"`
+	Skews   *bool       `json:"skews"`
+	Shaded  *bool       `json:"shaded"`
+	YScale  *float64    `json:"yScale"`
+	Level   *Range      `json:"level"`
+	Stats   []int       `json:"stats"` // penetration, firewall, integrity
+	Ability *AbilityDef `json:"ability"`
+	Item    *string     `json:"item"`    // Item type of a pickup, e.g. "INTEGRITY PATCH".
+	Count   *Range      `json:"count"`   // How many of the item a pickup holds.
+	Patrol  [][2]int    `json:"patrol"`  // Positions an npc, or a glitch that patrols, walks between.
+	Follows *bool       `json:"follows"` // If an npc starts out following the player.
+
+	Behaviors []actors.BehaviorDef `json:"behaviors"` // Replaces a glitch's behaviors, in the order they are tried.
+	ThinkRate *int                 `json:"thinkRate"` // Turns between a glitch's thinking.
+	Sight     *int                 `json:"sight"`     // How far a glitch sees the player from. 0 makes it never notice.
+	Patience  *int                 `json:"patience"`  // Turns a glitch keeps hunting the player out of sight.
+}
+
+type AbilityDef struct {
+	Name     string `json:"name"`
+	Tier     Range  `json:"tier"`
+	Turns    Range  `json:"turns"`
+	Cooldown Range  `json:"cooldown"`
+}
+
+// Link points an entity at a tagged entity in another room. If Flag is set, the link can only be used once that story flag is set.
+type Link struct {
+	Room    string `json:"room"`
+	Tag     string `json:"tag"`
+	OffsetX int    `json:"offsetX"`
+	OffsetY int    `json:"offsetY"`
+	Flag    string `json:"flag"`
+}
+
+// DoorDef is how a door is locked and what it looks like when it is. A door's destination and flag requirement come from its Link.
+type DoorDef struct {
+	Locked         bool   `json:"locked"`
+	Key            string `json:"key"` // Item type that unlocks the door.
+	LockedSprite   string `json:"lockedSprite"`
+	UnlockedSprite string `json:"unlockedSprite"`
+}
+
+// ShopDef is what a vendor terminal sells.
+type ShopDef struct {
+	Name     string     `json:"name"`
+	Greeting string     `json:"greeting"`
+	Stock    []StockDef `json:"stock"`
+}
+
+// StockDef is a single item in a shop. A price of 0 uses the item's usual price, and a missing count never runs out.
+type StockDef struct {
+	Item  string `json:"item"`
+	Price int    `json:"price"`
+	Count *int   `json:"count"`
+}
+
+// toShop creates the shop. Each vendor needs its own, as stock runs out.
+func (s ShopDef) toShop() *game.Shop {
+	shop := &game.Shop{
+		Name:     s.Name,
+		Greeting: s.Greeting,
+	}
+	for _, st := range s.Stock {
+		count := -1
+		if st.Count != nil {
+			count = *st.Count
+		}
+		shop.Stock = append(shop.Stock, &game.ShopStock{
+			Item:  game.ItemType(st.Item),
+			Price: st.Price,
+			Count: count,
+		})
+	}
+	return shop
+}
+
+// Range is an inclusive random range. In data it is either a single number or a [min, max] pair.
+type Range struct {
+	Min, Max int
+}
+
+func (r *Range) UnmarshalJSON(b []byte) error {
+	var v int
+	if err := json.Unmarshal(b, &v); err == nil {
+		r.Min, r.Max = v, v
+		return nil
+	}
+	var p [2]int
+	if err := json.Unmarshal(b, &p); err != nil {
+		return err
+	}
+	r.Min, r.Max = p[0], p[1]
+	return nil
+}
+
+func (r Range) Roll() int {
+	if r.Max <= r.Min {
+		return r.Min
+	}
+	return r.Min + rand.Intn(r.Max-r.Min+1)
+}
+
+// EntityPlacement is a single entity def placed at a position in a room.
+type EntityPlacement struct {
+	X   int    `json:"x"`
+	Y   int    `json:"y"`
+	Def string `json:"def"`
+}
+
+// create builds the actor for the def at the given position.
+func (e EntityDef) create(x, y int) (game.Actor, error) {
+	script := entityScripts[e.Script]
+	if e.Script != "" && script.OnCreate == nil && script.OnInteract == nil {
+		return nil, fmt.Errorf("missing entity script \"%s\"", e.Script)
+	}
+
+	interact := script.OnInteract
+	// Doors travel by themselves.
+	if interact == nil && e.Link != nil && e.Actor != "door" {
+		link := *e.Link
+		interact = func(w *game.World, r *game.Room, s, o game.Actor) commands.Command {
+			if link.Flag != "" && !w.Flag(link.Flag) {
+				return nil
+			}
+			return commands.Travel{
+				Room:    link.Room,
+				Tag:     link.Tag,
+				OffsetX: link.OffsetX,
+				OffsetY: link.OffsetY,
+				Target:  o,
+			}
+		}
+	}
+
+	if interact == nil && e.Shop != nil {
+		shop := e.Shop.toShop()
+		interact = func(w *game.World, r *game.Room, s, o game.Actor) commands.Command {
+			res.PlaySound("button")
+			return commands.Shop{Shop: shop}
+		}
+	}
+
+	var err error
+	a := actors.New(e.Actor, x, y, func(s game.Actor) {
+		if err = e.apply(s); err != nil {
+			return
+		}
+		if script.OnCreate != nil {
+			script.OnCreate(s)
+		}
+	}, interact)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (e EntityDef) apply(s game.Actor) error {
+	p := e.Properties
+	leveled := false
+	if g, ok := s.(*actors.Glitch); ok && e.Species != "" {
+		sp, ok := actors.GetSpecies(e.Species)
+		if !ok {
+			return fmt.Errorf("missing species \"%s\"", e.Species)
+		}
+		level := 0
+		if p.Level != nil {
+			level = p.Level.Roll()
+		}
+		if err := sp.Apply(g, level); err != nil {
+			return err
+		}
+		leveled = true
+	}
+
+	if e.Name != "" {
+		s.SetName(e.Name)
+	}
+	if e.Tag != "" {
+		s.SetTag(e.Tag)
+	}
+	if ss := s.SpriteStack(); ss != nil {
+		if e.Sprite != "" {
+			ss.SetSprite(e.Sprite)
+		}
+		if e.Rotation != 0 {
+			ss.Rotation = e.Rotation
+		}
+		if e.Properties.YScale != nil {
+			ss.YScale = *e.Properties.YScale
+		}
+		if e.Properties.Shaded != nil {
+			ss.Shaded = *e.Properties.Shaded
+		}
+	}
+
+	if g, ok := s.(*actors.Glitch); ok {
+		if p.Z != nil {
+			g.Z = *p.Z
+		}
+		if p.Floats != nil {
+			g.Floats = *p.Floats
+		}
+		if p.Wanders != nil {
+			g.Wanders = *p.Wanders
+		}
+		if p.Skews != nil {
+			g.Skews = *p.Skews
+		}
+		if p.ThinkRate != nil {
+			g.ThinkRate = *p.ThinkRate
+		}
+		if p.Sight != nil {
+			g.Sight = *p.Sight
+		}
+		if p.Patience != nil {
+			g.Patience = *p.Patience
+		}
+		if len(p.Behaviors) > 0 {
+			g.Behaviors = nil
+			for _, def := range p.Behaviors {
+				params := def.BehaviorParams
+				if params.Points == nil {
+					params.Points = p.Patrol
+				}
+				b, ok := actors.NewBehavior(def.Name, params)
+				if !ok {
+					return fmt.Errorf("missing behavior \"%s\"", def.Name)
+				}
+				g.Behaviors = append(g.Behaviors, b)
+			}
+		}
+		if p.Ability != nil {
+			g.SetAbility(&game.Ability{
+				Name:     p.Ability.Name,
+				Tier:     p.Ability.Tier.Roll(),
+				Turns:    p.Ability.Turns.Roll(),
+				Cooldown: p.Ability.Cooldown.Roll(),
+			})
+		}
+	}
+	if pickup, ok := s.(*actors.Pickup); ok {
+		if p.Item != nil {
+			pickup.Item = game.ItemType(*p.Item)
+			if e.Name == "" {
+				pickup.SetName(strings.ToLower(*p.Item))
+			}
+		}
+		if p.Count != nil {
+			pickup.Count = p.Count.Roll()
+		}
+	}
+	if n, ok := s.(*actors.NPC); ok {
+		if e.Dialogue != "" {
+			n.Dialogue = e.Dialogue
+		}
+		for _, l := range e.Lines {
+			n.Lines = append(n.Lines, actors.NPCLine{If: l.If, Text: l.Text})
+		}
+		if len(p.Patrol) > 0 {
+			n.Patrol = p.Patrol
+		}
+		if p.Follows != nil {
+			n.FollowsPlayer = *p.Follows
+		}
+	}
+	if d, ok := s.(*actors.Door); ok {
+		if e.Link != nil {
+			d.Room = e.Link.Room
+			d.Target = e.Link.Tag
+			d.OffsetX = e.Link.OffsetX
+			d.OffsetY = e.Link.OffsetY
+			d.Flag = e.Link.Flag
+		}
+		if e.Door != nil {
+			d.Locked = e.Door.Locked
+			d.Key = game.ItemType(e.Door.Key)
+			d.LockedSprite = e.Door.LockedSprite
+			d.UnlockedSprite = e.Door.UnlockedSprite
+		}
+	}
+	if g, ok := s.(*actors.Gate); ok {
+		g.Flags = e.Switch
+	}
+	if sw, ok := s.(*actors.Switch); ok && len(e.Switch) > 0 {
+		sw.Flag = e.Switch[0]
+	}
+	if c, ok := s.(game.CombatActor); ok && p.Level != nil && !leveled {
+		c.SetLevel(p.Level.Roll())
+	}
+	if c, ok := s.(interface{ SetStats(int, int, int) }); ok && len(p.Stats) == 3 {
+		c.SetStats(p.Stats[0], p.Stats[1], p.Stats[2])
+	}
+	return nil
+}
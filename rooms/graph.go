@@ -0,0 +1,20 @@
+package rooms
+
+import "sort"
+
+// Graph returns the rooms each room links to through its doors.
+func Graph() map[string][]string {
+	graph := make(map[string][]string)
+	for name := range rooms {
+		seen := make(map[string]bool)
+		for _, pl := range roomLinks(name) {
+			if _, ok := rooms[pl.link.Room]; !ok || seen[pl.link.Room] {
+				continue
+			}
+			seen[pl.link.Room] = true
+			graph[name] = append(graph[name], pl.link.Room)
+		}
+		sort.Strings(graph[name])
+	}
+	return graph
+}
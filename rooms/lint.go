@@ -0,0 +1,308 @@
+package rooms
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kettek/ebihack23/actors"
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/res"
+)
+
+// Problem is a mistake found in a room's data.
+type Problem struct {
+	Room    string
+	X, Y    int // -1 if the problem isn't at a position.
+	Message string
+	Warning bool // Warnings are things that might be on purpose, such as broken paths.
+}
+
+func (p Problem) String() string {
+	msg := p.Message
+	if p.Warning {
+		msg = "warning: " + msg
+	}
+	if p.X < 0 || p.Y < 0 {
+		return fmt.Sprintf("%s: %s", p.Room, msg)
+	}
+	return fmt.Sprintf("%s:%d,%d: %s", p.Room, p.X, p.Y, msg)
+}
+
+type linter struct {
+	problems []Problem
+}
+
+func (l *linter) add(room string, x, y int, format string, args ...interface{}) {
+	l.problems = append(l.problems, Problem{Room: room, X: x, Y: y, Message: fmt.Sprintf(format, args...)})
+}
+
+// Lint checks every registered room for mistakes that would otherwise only be found by playing.
+func Lint() []Problem {
+	l := &linter{}
+
+	names := GetRoomNames()
+	sort.Strings(names)
+	for _, name := range names {
+		l.lintRoom(name, rooms[name])
+	}
+	l.lintLinks(names)
+	l.lintReachable(names)
+
+	return l.problems
+}
+
+func (l *linter) lintRoom(name string, r *Room) {
+	if r.Script != "" {
+		if _, ok := roomScripts[r.Script]; !ok {
+			l.add(name, -1, -1, "unknown room script \"%s\"", r.Script)
+		}
+	}
+	if r.Song != "" && !res.HasSong(r.Song) {
+		l.add(name, -1, -1, "unknown song \"%s\"", r.Song)
+	}
+
+	width, height := r.Size()
+	for y, row := range r.TileGrid() {
+		if len(row) != width {
+			l.add(name, len(row), y, "ragged tile row, %d wide instead of %d", len(row), width)
+		}
+		for x, key := range row {
+			if key == "" {
+				continue
+			}
+			if _, ok := r.TileDefs[key]; !ok {
+				l.add(name, x, y, "tile \"%s\" is not in tileDefs", key)
+			}
+		}
+	}
+	for key, def := range r.TileDefs {
+		if !res.HasSprite(def.Sprite) {
+			l.add(name, -1, -1, "tile \"%s\" uses unknown sprite \"%s\"", key, def.Sprite)
+		}
+	}
+
+	if len(r.Entities) > height {
+		l.add(name, -1, -1, "%d entity rows for %d tile rows", len(r.Entities), height)
+	}
+	for _, p := range r.AllPlacements() {
+		if p.X >= width || p.Y >= height {
+			l.add(name, p.X, p.Y, "entity \"%s\" is outside of the room", p.Def)
+		}
+		if _, ok := r.EntityDefs[p.Def]; !ok {
+			l.add(name, p.X, p.Y, "entity \"%s\" is not in entityDefs", p.Def)
+		}
+	}
+	for key, def := range r.EntityDefs {
+		if !actors.Exists(def.Actor) {
+			l.add(name, -1, -1, "entity \"%s\" uses unknown actor \"%s\"", key, def.Actor)
+		}
+		if def.Sprite != "" && !res.HasSprite(def.Sprite) {
+			l.add(name, -1, -1, "entity \"%s\" uses unknown sprite \"%s\"", key, def.Sprite)
+		}
+		if def.Species != "" {
+			if _, ok := actors.GetSpecies(def.Species); !ok {
+				l.add(name, -1, -1, "entity \"%s\" is of unknown species \"%s\"", key, def.Species)
+			} else if def.Actor != "glitch" {
+				l.add(name, -1, -1, "entity \"%s\" has a species but isn't a glitch", key)
+			}
+		}
+		if def.Properties.Item != nil {
+			if _, ok := game.ItemDescriptions[game.ItemType(*def.Properties.Item)]; !ok {
+				l.add(name, -1, -1, "entity \"%s\" holds unknown item \"%s\"", key, *def.Properties.Item)
+			}
+		}
+		if def.Shop != nil {
+			for _, st := range def.Shop.Stock {
+				if _, ok := game.ItemPrices[game.ItemType(st.Item)]; !ok {
+					l.add(name, -1, -1, "entity \"%s\" sells unknown item \"%s\"", key, st.Item)
+				}
+			}
+		}
+		if def.Dialogue != "" {
+			if _, err := game.LoadDialogue(def.Dialogue); err != nil {
+				l.add(name, -1, -1, "entity \"%s\" has a bad dialogue: %s", key, err)
+			}
+		}
+		if def.Door != nil {
+			if def.Door.Key != "" {
+				if _, ok := game.ItemDescriptions[game.ItemType(def.Door.Key)]; !ok {
+					l.add(name, -1, -1, "entity \"%s\" is unlocked by unknown item \"%s\"", key, def.Door.Key)
+				}
+			}
+			for _, s := range []string{def.Door.LockedSprite, def.Door.UnlockedSprite} {
+				if s != "" && !res.HasSprite(s) {
+					l.add(name, -1, -1, "entity \"%s\" uses unknown sprite \"%s\"", key, s)
+				}
+			}
+		}
+		if def.Actor == "door" && def.Link == nil && (def.Door == nil || !def.Door.Locked) {
+			l.add(name, -1, -1, "door \"%s\" has no link and isn't locked, so it does nothing", key)
+			l.problems[len(l.problems)-1].Warning = true
+		}
+		if (def.Actor == "gate" || def.Actor == "switch") && len(def.Switch) == 0 {
+			l.add(name, -1, -1, "entity \"%s\" is a %s without any switch flags", key, def.Actor)
+		}
+		for _, b := range def.Properties.Behaviors {
+			if _, ok := actors.NewBehavior(b.Name, actors.BehaviorParams{}); !ok {
+				l.add(name, -1, -1, "entity \"%s\" uses unknown behavior \"%s\"", key, b.Name)
+			} else if b.Name == "patrol" && len(def.Properties.Patrol) == 0 {
+				l.add(name, -1, -1, "entity \"%s\" patrols without any patrol points", key)
+			}
+		}
+		for _, p := range def.Properties.Patrol {
+			if !r.walkable(p[0], p[1]) {
+				l.add(name, p[0], p[1], "entity \"%s\" patrols to a tile that can't be walked on", key)
+			}
+		}
+		if def.Script != "" {
+			if _, ok := entityScripts[def.Script]; !ok {
+				l.add(name, -1, -1, "entity \"%s\" uses unknown script \"%s\"", key, def.Script)
+			}
+		}
+	}
+
+	for _, t := range r.Triggers {
+		if t.Script != "" {
+			if _, ok := triggerScripts[t.Script]; !ok {
+				l.add(name, t.X, t.Y, "trigger \"%s\" uses unknown script \"%s\"", t.Tag, t.Script)
+			}
+		}
+		if t.X < 0 || t.Y < 0 || t.X >= width || t.Y >= height {
+			l.add(name, t.X, t.Y, "trigger \"%s\" is outside of the room", t.Tag)
+		}
+	}
+}
+
+// placedLink is a link as placed in a room.
+type placedLink struct {
+	room string
+	x, y int
+	tag  string
+	link Link
+}
+
+func roomLinks(name string) (links []placedLink) {
+	r := rooms[name]
+	for _, p := range r.AllPlacements() {
+		def, ok := r.EntityDefs[p.Def]
+		if !ok || def.Link == nil {
+			continue
+		}
+		links = append(links, placedLink{room: name, x: p.X, y: p.Y, tag: def.Tag, link: *def.Link})
+	}
+	return links
+}
+
+// findTag returns the position of the first entity in the room with the given tag.
+func findTag(r *Room, tag string) (int, int, bool) {
+	for _, p := range r.AllPlacements() {
+		if def, ok := r.EntityDefs[p.Def]; ok && def.Tag == tag {
+			return p.X, p.Y, true
+		}
+	}
+	return 0, 0, false
+}
+
+func (l *linter) lintLinks(names []string) {
+	for _, name := range names {
+		for _, pl := range roomLinks(name) {
+			target, ok := rooms[pl.link.Room]
+			if !ok {
+				l.add(name, pl.x, pl.y, "link to unknown room \"%s\"", pl.link.Room)
+				continue
+			}
+			tx, ty, ok := findTag(target, pl.link.Tag)
+			if !ok {
+				l.add(name, pl.x, pl.y, "link to \"%s\" has no entity tagged \"%s\", travelers would land at 0,0", pl.link.Room, pl.link.Tag)
+				continue
+			}
+			if !target.walkable(tx+pl.link.OffsetX, ty+pl.link.OffsetY) {
+				l.add(name, pl.x, pl.y, "link to \"%s\" lands on %d,%d, which can't be walked on", pl.link.Room, tx+pl.link.OffsetX, ty+pl.link.OffsetY)
+			}
+			// And make sure there is a way back.
+			back := false
+			for _, other := range roomLinks(pl.link.Room) {
+				if other.link.Room == name {
+					if _, _, ok := findTag(rooms[name], other.link.Tag); ok {
+						back = true
+						break
+					}
+				}
+			}
+			if !back {
+				l.add(name, pl.x, pl.y, "link to \"%s\" has no link back", pl.link.Room)
+			}
+		}
+	}
+}
+
+func (r *Room) walkable(x, y int) bool {
+	grid := r.TileGrid()
+	if y < 0 || y >= len(grid) || x < 0 || x >= len(grid[y]) {
+		return false
+	}
+	def, ok := r.TileDefs[grid[y][x]]
+	return ok && !def.BlocksMove
+}
+
+// lintReachable flood-fills every room from where the player can arrive and reports any floor that can't be walked to.
+func (l *linter) lintReachable(names []string) {
+	entries := make(map[string][][2]int)
+	for _, name := range names {
+		r := rooms[name]
+		for _, p := range r.AllPlacements() {
+			if def, ok := r.EntityDefs[p.Def]; ok && def.Actor == "player" {
+				entries[name] = append(entries[name], [2]int{p.X, p.Y})
+			}
+		}
+		for _, pl := range roomLinks(name) {
+			target, ok := rooms[pl.link.Room]
+			if !ok {
+				continue
+			}
+			if tx, ty, ok := findTag(target, pl.link.Tag); ok {
+				entries[pl.link.Room] = append(entries[pl.link.Room], [2]int{tx + pl.link.OffsetX, ty + pl.link.OffsetY})
+			}
+		}
+	}
+
+	for _, name := range names {
+		r := rooms[name]
+		if len(entries[name]) == 0 {
+			l.add(name, -1, -1, "room can't be entered, nothing links to it")
+			continue
+		}
+		width, height := r.Size()
+		seen := make([][]bool, height)
+		for y := range seen {
+			seen[y] = make([]bool, width)
+		}
+		open := append([][2]int{}, entries[name]...)
+		for len(open) > 0 {
+			p := open[len(open)-1]
+			open = open[:len(open)-1]
+			x, y := p[0], p[1]
+			if !r.walkable(x, y) || seen[y][x] {
+				continue
+			}
+			seen[y][x] = true
+			open = append(open, [2]int{x + 1, y}, [2]int{x - 1, y}, [2]int{x, y + 1}, [2]int{x, y - 1})
+		}
+		unreachable := 0
+		fx, fy := -1, -1
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if r.walkable(x, y) && !seen[y][x] {
+					if unreachable == 0 {
+						fx, fy = x, y
+					}
+					unreachable++
+				}
+			}
+		}
+		if unreachable > 0 {
+			l.add(name, fx, fy, "%d floor tiles can't be reached", unreachable)
+			l.problems[len(l.problems)-1].Warning = true
+		}
+	}
+}
@@ -1,61 +1,124 @@
 package rooms
 
 import (
+	"fmt"
 	"image/color"
 	"strings"
+	"time"
 
 	"github.com/kettek/ebihack23/actors"
 	"github.com/kettek/ebihack23/game"
 )
 
+// Room is a room definition as loaded from res/rooms. Tiles and Entities are rows of characters that index into TileDefs and EntityDefs.
 type Room struct {
-	tiles     string
-	tileDefs  TileDefs
-	entities  string
-	entityMap EntityDefs
-	metadata  map[string]interface{}
-	enter     func(w *game.World, r *game.Room)
-	leave     func(w *game.World, r *game.Room)
-	update    func(w *game.World, r *game.Room)
-	turn      func(w *game.World, r *game.Room)
-	name      string
-	song      string
-	darkness  float64
-	color     color.NRGBA
+	Name       string            `json:"name"`
+	Song       string            `json:"song"`
+	Darkness   float64           `json:"darkness"`
+	Color      color.NRGBA       `json:"color"`
+	Script     string            `json:"script"` // Name of a room script to attach.
+	Tiles      []string          `json:"tiles"`
+	TileDefs   TileDefs          `json:"tileDefs"`
+	Entities   []string          `json:"entities"`
+	EntityDefs EntityDefs        `json:"entityDefs"`
+	Placements []EntityPlacement `json:"placements"` // Placed in addition to anything in Entities.
+	Triggers   []TriggerDef      `json:"triggers"`
+	grid       [][]string        // Tile keys by row, for rooms that don't use single-character Tiles.
 }
 
-func (r *Room) ToGameRoom() *game.Room {
-	lines := strings.Split(r.tiles, "\n")
-	lines = lines[1:]
+// TriggerDef is a trigger area in a room. A trigger either shows a message, calls a trigger script, or both.
+type TriggerDef struct {
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	W       int    `json:"w"`
+	H       int    `json:"h"`
+	Tag     string `json:"tag"`
+	Once    bool   `json:"once"`
+	Player  bool   `json:"player"` // Only the player can fire it.
+	Message string `json:"message"`
+	Script  string `json:"script"`
+}
+
+// TileGrid returns the TileDefs key of every tile, by row. An empty key is an empty tile.
+func (r *Room) TileGrid() [][]string {
+	if r.grid != nil {
+		return r.grid
+	}
+	grid := make([][]string, len(r.Tiles))
+	for y, row := range r.Tiles {
+		for _, char := range row {
+			if char == ' ' || char == '\t' {
+				grid[y] = append(grid[y], "")
+			} else {
+				grid[y] = append(grid[y], string(char))
+			}
+		}
+	}
+	return grid
+}
+
+// Size returns the room's size in tiles.
+func (r *Room) Size() (int, int) {
+	grid := r.TileGrid()
 	width := 0
-	for i, line := range lines {
-		c := strings.TrimLeft(line, "\t")
-		if len(c) > width {
-			width = len(c)
+	for _, row := range grid {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	return width, len(grid)
+}
+
+// AllPlacements returns every placement in the room, both from the Entities rows and the explicit Placements.
+func (r *Room) AllPlacements() []EntityPlacement {
+	var placements []EntityPlacement
+	for y, row := range r.Entities {
+		for x, char := range row {
+			if char == ' ' || char == '\t' {
+				continue
+			}
+			placements = append(placements, EntityPlacement{X: x, Y: y, Def: string(char)})
 		}
-		lines[i] = c
 	}
-	height := len(lines) - 1
-	g := game.NewRoom(width, height)
-	g.OnUpdate = r.update
-	g.OnEnter = r.enter
-	g.OnLeave = r.leave
-	g.OnTurn = r.turn
-	g.Song = r.song
-	if r.color.A == 0 {
+	return append(placements, r.Placements...)
+}
+
+// ToGameRoom builds the room. Anything the data refers to that doesn't exist, such as a script or species, is returned as an error.
+func (r *Room) ToGameRoom(clock *game.Clock) (*game.Room, error) {
+	width, height := r.Size()
+	g := game.NewRoom(width, height, clock)
+	if r.Script != "" {
+		script, ok := roomScripts[r.Script]
+		if !ok {
+			return nil, fmt.Errorf("missing room script \"%s\"", r.Script)
+		}
+		g.OnUpdate = script.Update
+		g.OnEnter = script.Enter
+		g.OnLeave = script.Leave
+		g.OnTurn = script.Turn
+	}
+	g.Song = r.Song
+	if r.Color.A == 0 {
 		g.Color = color.NRGBA{0, 0, 0, 255}
 	} else {
-		g.Color = r.color
+		g.Color = r.Color
+	}
+	g.Darkness = r.Darkness
+	g.Name = strings.ToUpper(r.Name)
+	for _, t := range r.Triggers {
+		trigger, err := t.toTrigger()
+		if err != nil {
+			return nil, err
+		}
+		g.AddTrigger(trigger)
 	}
-	g.Darkness = r.darkness
-	g.Name = strings.ToUpper(r.name)
 
-	for y, line := range lines {
-		for x, char := range line {
-			if char == ' ' || char == '\t' {
+	for y, row := range r.TileGrid() {
+		for x, key := range row {
+			if key == "" {
 				continue
 			}
-			tileDef, ok := r.tileDefs[string(char)]
+			tileDef, ok := r.TileDefs[key]
 			if !ok {
 				continue
 			}
@@ -65,32 +128,60 @@ func (r *Room) ToGameRoom() *game.Room {
 			}
 			g.Tiles[y][x].BlocksMove = tileDef.BlocksMove
 			g.Tiles[y][x].Name = tileDef.Name
+			g.Tiles[y][x].Switches = tileDef.Switch
+			if tileDef.Plate != "" {
+				g.AddTrigger(game.NewPlateTrigger(x, y, tileDef.Plate))
+			}
 		}
 	}
 
 	// Make them entities.
-	lines = strings.Split(r.entities, "\n")
-	lines = lines[1 : len(lines)-1]
-	for y, line := range lines {
-		l := strings.TrimLeft(line, "\t")
-		for x, char := range l {
-			if char == ' ' {
-				continue
-			}
-			entity, ok := r.entityMap[string(char)]
-			if !ok {
-				continue
-			}
-			actor := actors.New(entity.Actor, x, y, entity.OnCreate, entity.OnInteract)
-			if actor == nil {
-				continue
-			}
-			if _, ok := actor.(*actors.Glitch); ok {
-				g.MaxGlitches++
-			}
-			g.Actors = append(g.Actors, actor)
+	for _, p := range r.AllPlacements() {
+		entity, ok := r.EntityDefs[p.Def]
+		if !ok {
+			continue
+		}
+		actor, err := entity.create(p.X, p.Y)
+		if err != nil {
+			return nil, fmt.Errorf("%d,%d: %w", p.X, p.Y, err)
 		}
+		if actor == nil {
+			continue
+		}
+		if _, ok := actor.(*actors.Glitch); ok {
+			g.MaxGlitches++
+		}
+		g.Actors = append(g.Actors, actor)
 	}
 
-	return g
+	return g, nil
+}
+
+func (t TriggerDef) toTrigger() (*game.Trigger, error) {
+	trigger := game.NewAreaTrigger(t.X, t.Y, t.W, t.H)
+	trigger.Tag = t.Tag
+	trigger.Once = t.Once
+	if t.Player {
+		trigger.Filter = game.TriggerPlayer
+	}
+	var script game.TriggerFunc
+	if t.Script != "" {
+		var ok bool
+		if script, ok = triggerScripts[t.Script]; !ok {
+			return nil, fmt.Errorf("missing trigger script \"%s\"", t.Script)
+		}
+	}
+	msg := t.Message
+	trigger.OnEnter = func(w *game.World, r *game.Room, t *game.Trigger, a game.Actor) {
+		if msg != "" {
+			w.Play(r, game.ShowMessage(game.Message{
+				Text:     msg,
+				Duration: 4 * time.Second,
+			}))
+		}
+		if script != nil {
+			script(w, r, t, a)
+		}
+	}
+	return trigger, nil
 }
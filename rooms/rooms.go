@@ -1,23 +1,55 @@
 package rooms
 
-import "github.com/kettek/ebihack23/game"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
 
-func BuildRoom(name string) *game.Room {
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/res"
+)
+
+// BuildRoom builds a fresh copy of the named room.
+func BuildRoom(name string, clock *game.Clock) (*game.Room, error) {
 	room, ok := rooms[name]
 	if !ok {
-		return nil
+		if loadErr != nil {
+			return nil, fmt.Errorf("no room \"%s\", some rooms failed to load: %w", name, loadErr)
+		}
+		return nil, fmt.Errorf("no room \"%s\"", name)
+	}
+	gRoom, err := room.ToGameRoom(clock)
+	if err != nil {
+		return nil, fmt.Errorf("room %s: %w", name, err)
 	}
-	gRoom := room.ToGameRoom()
-	return gRoom
+	gRoom.ID = name
+	return gRoom, nil
 }
 
-func GetRoom(name string) *game.Room {
-	room, ok := cachedRooms[name]
-	if !ok {
-		room = BuildRoom(name)
-		cachedRooms[name] = room
+// GetRoom returns the named room, building it the first time it is asked for.
+func GetRoom(name string, clock *game.Clock) (*game.Room, error) {
+	if room, ok := cachedRooms[name]; ok {
+		return room, nil
+	}
+	room, err := BuildRoom(name, clock)
+	if err != nil {
+		return nil, err
 	}
-	return room
+	cachedRooms[name] = room
+	return room, nil
+}
+
+// CachedRoom returns the named room if it has already been built, without building it.
+func CachedRoom(name string) (*game.Room, bool) {
+	room, ok := cachedRooms[name]
+	return room, ok
+}
+
+// ClearCache forgets every room built so far, so the next GetRoom builds it fresh.
+func ClearCache() {
+	cachedRooms = make(map[string]*game.Room)
 }
 
 func GetRoomNames() (names []string) {
@@ -27,5 +59,55 @@ func GetRoomNames() (names []string) {
 	return
 }
 
-var rooms = make(map[string]Room)
+// LoadRoom parses a room file. Its name is the file name without the extension.
+func LoadRoom(file string) (string, *Room, error) {
+	b, err := res.FS.ReadFile(file)
+	if err != nil {
+		return "", nil, err
+	}
+	r := &Room{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return "", nil, fmt.Errorf("room %s: %w", file, err)
+	}
+	return strings.TrimSuffix(path.Base(file), path.Ext(file)), r, nil
+}
+
+// LoadError returns whatever went wrong while loading the room files, if anything. Rooms that failed to load are left out.
+func LoadError() error {
+	return loadErr
+}
+
+func loadRooms() error {
+	entries, err := res.FS.ReadDir("rooms")
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, e := range entries {
+		var name string
+		var r *Room
+		var err error
+		switch path.Ext(e.Name()) {
+		case ".json":
+			name, r, err = LoadRoom(path.Join("rooms", e.Name()))
+		case ".tmx":
+			name, r, err = LoadTMXRoom(path.Join("rooms", e.Name()))
+		default:
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rooms[name] = r
+	}
+	return errors.Join(errs...)
+}
+
+func init() {
+	loadErr = loadRooms()
+}
+
+var loadErr error
+var rooms = make(map[string]*Room)
 var cachedRooms = make(map[string]*game.Room)
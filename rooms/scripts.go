@@ -0,0 +1,24 @@
+package rooms
+
+import (
+	"github.com/kettek/ebihack23/actors"
+	"github.com/kettek/ebihack23/game"
+)
+
+// RoomScript is the scripted behaviour a room file can attach by name.
+type RoomScript struct {
+	Enter  func(w *game.World, r *game.Room)
+	Leave  func(w *game.World, r *game.Room)
+	Update func(w *game.World, r *game.Room)
+	Turn   func(w *game.World, r *game.Room)
+}
+
+// EntityScript is the scripted behaviour an entity def can attach by name. OnCreate is called after the def's own properties are applied, and OnInteract replaces any link.
+type EntityScript struct {
+	OnCreate   actors.CreateFunc
+	OnInteract actors.InteractFunc
+}
+
+var roomScripts = make(map[string]RoomScript)
+var entityScripts = make(map[string]EntityScript)
+var triggerScripts = make(map[string]game.TriggerFunc)
@@ -0,0 +1,39 @@
+package rooms
+
+import (
+	"fmt"
+
+	"github.com/kettek/ebihack23/commands"
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/res"
+)
+
+// useTerminal powers up the room's terminal and runs the given dialogue on it, powering it back down once the dialogue ends.
+func useTerminal(r *game.Room, dialogue string, handlers map[string]func(w *game.World)) commands.Command {
+	d, err := game.LoadDialogue(dialogue)
+	if err != nil {
+		fmt.Println("couldn't use terminal:", err)
+		return nil
+	}
+	for k, h := range handlers {
+		d.Handlers[k] = h
+	}
+
+	r.GetActorByTag("terminal").SpriteStack().SetSprite("terminal")
+	//res.PlaySound("button")
+	poweron := res.PlaySound("poweron")
+	powered := res.GetSound("powered")
+	poweroff := res.GetSound("poweroff")
+	poweron.Next = powered
+
+	powered.Looping = true
+	powered.Next = poweroff
+
+	d.OnEnd = func(w *game.World) {
+		poweron.Next = poweroff // Set poweron's next to poweroff just in case the player exits the menu quickly.
+		powered.Looping = false
+		powered.Pause()
+		r.GetActorByTag("terminal").SpriteStack().SetSprite("terminal-off")
+	}
+	return commands.Dialogue{Dialogue: d}
+}
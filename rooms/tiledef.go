@@ -1,10 +1,12 @@
 package rooms
 
 type TileDef struct {
-	Name       string
-	Sprite     string
-	BlocksMove bool
-	Rotation   float64
+	Name       string   `json:"name"`
+	Sprite     string   `json:"sprite"`
+	BlocksMove bool     `json:"blocksMove"`
+	Rotation   float64  `json:"rotation"`
+	Plate      string   `json:"plate"`  // Flag held while something stands on the tile.
+	Switch     []string `json:"switch"` // Flag conditions for the tile to be there, such as for bridges.
 }
 
 type TileDefs map[string]TileDef
@@ -0,0 +1,506 @@
+package rooms
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/kettek/ebihack23/actors"
+	"github.com/kettek/ebihack23/res"
+)
+
+// Tiled TMX import. Only the bits we care about are read:
+//
+//   - map properties: name, song, darkness, color, script
+//   - tileset tile properties: name, sprite, blocksMove, rotation, plate, switch. If there is no sprite property, the tile's image name is used.
+//   - tile layers (csv encoded). Later layers overwrite earlier ones.
+//   - objects: the class (or type) is the actor. Objects of class "trigger" become triggers. Everything else that an EntityDef has is read from the object's properties.
+//     Shop stock is a "shop" property of "ITEM:price[:count]" separated by semicolons.
+//     Npc patrols are a "patrol" property of "x,y" tile positions separated by semicolons, and "line" is a single thing for it to say.
+//     Doors read locked, key, lockedSprite and unlockedSprite properties alongside their link.
+//     Glitches can give a "species" property along with a "level" range instead of their sprite, stats and ability.
+//     Glitch "behaviors" are names separated by commas, tried in order with their default tuning. Patrolling glitches use the "patrol" property.
+//     A glitch's "sight" and "patience" are how far it sees the player from and how many turns it keeps hunting it out of sight.
+//     Gate and switch flags, as well as tile switches, are a "switch" property of flag conditions separated by commas.
+
+type tmxMap struct {
+	Width        int              `xml:"width,attr"`
+	Height       int              `xml:"height,attr"`
+	TileWidth    int              `xml:"tilewidth,attr"`
+	TileHeight   int              `xml:"tileheight,attr"`
+	Properties   []tmxProperty    `xml:"properties>property"`
+	Tilesets     []tmxTileset     `xml:"tileset"`
+	Layers       []tmxLayer       `xml:"layer"`
+	ObjectGroups []tmxObjectGroup `xml:"objectgroup"`
+}
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type tmxTileset struct {
+	FirstGID int       `xml:"firstgid,attr"`
+	Source   string    `xml:"source,attr"`
+	Tiles    []tmxTile `xml:"tile"`
+}
+
+type tmxTile struct {
+	ID         int           `xml:"id,attr"`
+	Image      tmxImage      `xml:"image"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+}
+
+type tmxLayer struct {
+	Name string  `xml:"name,attr"`
+	Data tmxData `xml:"data"`
+}
+
+type tmxData struct {
+	Encoding string `xml:"encoding,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type tmxObjectGroup struct {
+	Name    string      `xml:"name,attr"`
+	Objects []tmxObject `xml:"object"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Class      string        `xml:"class,attr"`
+	Type       string        `xml:"type,attr"` // Pre-1.9 Tiled name for class.
+	GID        uint32        `xml:"gid,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+// Tiled stores flip flags in the top bits of a gid.
+const tmxFlipMask = 0x1fffffff
+
+type tmxProperties map[string]string
+
+func toProperties(props []tmxProperty) tmxProperties {
+	m := make(tmxProperties)
+	for _, p := range props {
+		m[p.Name] = p.Value
+	}
+	return m
+}
+
+func (p tmxProperties) bool(name string) (bool, bool, error) {
+	s, ok := p[name]
+	if !ok {
+		return false, false, nil
+	}
+	v, err := strconv.ParseBool(s)
+	return v, true, err
+}
+
+func (p tmxProperties) float(name string) (float64, bool, error) {
+	s, ok := p[name]
+	if !ok {
+		return 0, false, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v, true, err
+}
+
+func (p tmxProperties) int(name string) (int, bool, error) {
+	s, ok := p[name]
+	if !ok {
+		return 0, false, nil
+	}
+	v, err := strconv.Atoi(s)
+	return v, true, err
+}
+
+// list reads a comma separated property, leaving out empty entries.
+func (p tmxProperties) list(name string) (l []string) {
+	for _, v := range strings.Split(p[name], ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			l = append(l, v)
+		}
+	}
+	return l
+}
+
+// rng reads a range property, either "n" or "min-max".
+func (p tmxProperties) rng(name string) (*Range, error) {
+	s, ok := p[name]
+	if !ok {
+		return nil, nil
+	}
+	lo, hi, found := strings.Cut(s, "-")
+	if !found {
+		hi = lo
+	}
+	min, err := strconv.Atoi(strings.TrimSpace(lo))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	max, err := strconv.Atoi(strings.TrimSpace(hi))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &Range{Min: min, Max: max}, nil
+}
+
+// color reads a Tiled color property, "#AARRGGBB" or "#RRGGBB".
+func (p tmxProperties) color(name string) (color.NRGBA, bool, error) {
+	s, ok := p[name]
+	if !ok {
+		return color.NRGBA{}, false, nil
+	}
+	s = strings.TrimPrefix(s, "#")
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, true, err
+	}
+	c := color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}
+	if len(s) == 8 {
+		c.A = uint8(v >> 24)
+	}
+	return c, true, nil
+}
+
+// LoadTMXRoom parses a Tiled map into a room. Its name is the file name without the extension.
+func LoadTMXRoom(file string) (string, *Room, error) {
+	name := strings.TrimSuffix(path.Base(file), path.Ext(file))
+	b, err := res.FS.ReadFile(file)
+	if err != nil {
+		return "", nil, err
+	}
+	var m tmxMap
+	if err := xml.Unmarshal(b, &m); err != nil {
+		return "", nil, fmt.Errorf("room %s: %w", file, err)
+	}
+	r, err := m.toRoom(path.Dir(file))
+	if err != nil {
+		return "", nil, fmt.Errorf("room %s: %w", file, err)
+	}
+	return name, r, nil
+}
+
+func (m *tmxMap) toRoom(dir string) (*Room, error) {
+	r := &Room{
+		TileDefs:   make(TileDefs),
+		EntityDefs: make(EntityDefs),
+	}
+
+	props := toProperties(m.Properties)
+	r.Name = props["name"]
+	r.Song = props["song"]
+	r.Script = props["script"]
+	if v, ok, err := props.float("darkness"); err != nil {
+		return nil, err
+	} else if ok {
+		r.Darkness = v
+	}
+	if v, ok, err := props.color("color"); err != nil {
+		return nil, err
+	} else if ok {
+		r.Color = v
+	}
+
+	// Tilesets become tile defs keyed by gid.
+	for _, ts := range m.Tilesets {
+		if ts.Source != "" {
+			// External tileset, so read its tiles in.
+			b, err := res.FS.ReadFile(path.Join(dir, ts.Source))
+			if err != nil {
+				return nil, err
+			}
+			var ext tmxTileset
+			if err := xml.Unmarshal(b, &ext); err != nil {
+				return nil, fmt.Errorf("%s: %w", ts.Source, err)
+			}
+			ts.Tiles = ext.Tiles
+		}
+		for _, t := range ts.Tiles {
+			def, err := t.toTileDef()
+			if err != nil {
+				return nil, err
+			}
+			r.TileDefs[strconv.Itoa(ts.FirstGID+t.ID)] = def
+		}
+	}
+
+	r.grid = make([][]string, m.Height)
+	for y := range r.grid {
+		r.grid[y] = make([]string, m.Width)
+	}
+	for _, l := range m.Layers {
+		if l.Data.Encoding != "csv" {
+			return nil, fmt.Errorf("layer %s: unsupported encoding \"%s\", save as csv", l.Name, l.Data.Encoding)
+		}
+		for i, s := range strings.Split(l.Data.Value, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(s, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("layer %s: %w", l.Name, err)
+			}
+			gid &= tmxFlipMask
+			if gid == 0 || i >= m.Width*m.Height {
+				continue
+			}
+			r.grid[i/m.Width][i%m.Width] = strconv.Itoa(int(gid))
+		}
+	}
+
+	for _, g := range m.ObjectGroups {
+		for _, o := range g.Objects {
+			x := int(o.X) / m.TileWidth
+			y := int(o.Y) / m.TileHeight
+			if o.GID != 0 {
+				// Tile objects are positioned by their bottom-left corner.
+				y = (int(o.Y) - 1) / m.TileHeight
+			}
+			class := o.Class
+			if class == "" {
+				class = o.Type
+			}
+			if class == "trigger" {
+				t, err := o.toTriggerDef(x, y, m.TileWidth, m.TileHeight)
+				if err != nil {
+					return nil, err
+				}
+				r.Triggers = append(r.Triggers, t)
+				continue
+			}
+			def, err := o.toEntityDef(class)
+			if err != nil {
+				return nil, err
+			}
+			key := "object-" + strconv.Itoa(o.ID)
+			r.EntityDefs[key] = def
+			r.Placements = append(r.Placements, EntityPlacement{X: x, Y: y, Def: key})
+		}
+	}
+
+	return r, nil
+}
+
+func (t tmxTile) toTileDef() (TileDef, error) {
+	props := toProperties(t.Properties)
+	def := TileDef{
+		Name:   props["name"],
+		Sprite: props["sprite"],
+	}
+	if def.Sprite == "" && t.Image.Source != "" {
+		def.Sprite = strings.TrimSuffix(path.Base(t.Image.Source), path.Ext(t.Image.Source))
+	}
+	if v, ok, err := props.bool("blocksMove"); err != nil {
+		return def, err
+	} else if ok {
+		def.BlocksMove = v
+	}
+	if v, ok, err := props.float("rotation"); err != nil {
+		return def, err
+	} else if ok {
+		def.Rotation = v
+	}
+	def.Plate = props["plate"]
+	def.Switch = props.list("switch")
+	return def, nil
+}
+
+func (o tmxObject) toTriggerDef(x, y, tw, th int) (TriggerDef, error) {
+	props := toProperties(o.Properties)
+	t := TriggerDef{
+		X:       x,
+		Y:       y,
+		W:       int(o.Width) / tw,
+		H:       int(o.Height) / th,
+		Tag:     o.Name,
+		Message: props["message"],
+		Script:  props["script"],
+	}
+	var err error
+	if t.Once, _, err = props.bool("once"); err != nil {
+		return t, err
+	}
+	if t.Player, _, err = props.bool("player"); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+func (o tmxObject) toEntityDef(actor string) (EntityDef, error) {
+	props := toProperties(o.Properties)
+	def := EntityDef{
+		Actor:   actor,
+		Name:    o.Name,
+		Tag:     props["tag"],
+		Sprite:  props["sprite"],
+		Script:  props["script"],
+		Species: props["species"],
+	}
+	var err error
+	if def.Rotation, _, err = props.float("rotation"); err != nil {
+		return def, err
+	}
+
+	p := &def.Properties
+	if v, ok, err := props.int("z"); err != nil {
+		return def, err
+	} else if ok {
+		p.Z = &v
+	}
+	for name, dst := range map[string]**bool{"floats": &p.Floats, "wanders": &p.Wanders, "skews": &p.Skews, "shaded": &p.Shaded} {
+		if v, ok, err := props.bool(name); err != nil {
+			return def, err
+		} else if ok {
+			*dst = &v
+		}
+	}
+	if v, ok, err := props.float("yScale"); err != nil {
+		return def, err
+	} else if ok {
+		p.YScale = &v
+	}
+	if p.Level, err = props.rng("level"); err != nil {
+		return def, err
+	}
+	if s, ok := props["stats"]; ok {
+		// penetration,firewall,integrity
+		for _, v := range strings.Split(s, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return def, fmt.Errorf("stats: %w", err)
+			}
+			p.Stats = append(p.Stats, n)
+		}
+	}
+	if name, ok := props["ability"]; ok {
+		a := &AbilityDef{Name: name}
+		for prop, dst := range map[string]*Range{"abilityTier": &a.Tier, "abilityTurns": &a.Turns, "abilityCooldown": &a.Cooldown} {
+			v, err := props.rng(prop)
+			if err != nil {
+				return def, err
+			} else if v != nil {
+				*dst = *v
+			}
+		}
+		p.Ability = a
+	}
+
+	if item, ok := props["item"]; ok {
+		p.Item = &item
+	}
+	if p.Count, err = props.rng("count"); err != nil {
+		return def, err
+	}
+
+	if room, ok := props["room"]; ok {
+		def.Link = &Link{
+			Room: room,
+			Tag:  props["linkTag"],
+			Flag: props["flag"],
+		}
+		if def.Link.Tag == "" {
+			def.Link.Tag = def.Tag
+		}
+		if def.Link.OffsetX, _, err = props.int("offsetX"); err != nil {
+			return def, err
+		}
+		if def.Link.OffsetY, _, err = props.int("offsetY"); err != nil {
+			return def, err
+		}
+	}
+
+	locked, lockedOk, err := props.bool("locked")
+	if err != nil {
+		return def, err
+	}
+	if lockedOk || props["key"] != "" || props["lockedSprite"] != "" || props["unlockedSprite"] != "" {
+		def.Door = &DoorDef{
+			Locked:         locked,
+			Key:            props["key"],
+			LockedSprite:   props["lockedSprite"],
+			UnlockedSprite: props["unlockedSprite"],
+		}
+	}
+
+	def.Switch = props.list("switch")
+	def.Dialogue = props["dialogue"]
+	if line, ok := props["line"]; ok {
+		def.Lines = append(def.Lines, LineDef{Text: line})
+	}
+	if v, ok, err := props.bool("follows"); err != nil {
+		return def, err
+	} else if ok {
+		p.Follows = &v
+	}
+	if s, ok := props["patrol"]; ok {
+		// x,y;x,y;...
+		for _, pt := range strings.Split(s, ";") {
+			if pt = strings.TrimSpace(pt); pt == "" {
+				continue
+			}
+			xs, ys, _ := strings.Cut(pt, ",")
+			x, err := strconv.Atoi(strings.TrimSpace(xs))
+			if err != nil {
+				return def, fmt.Errorf("patrol: %w", err)
+			}
+			y, err := strconv.Atoi(strings.TrimSpace(ys))
+			if err != nil {
+				return def, fmt.Errorf("patrol: %w", err)
+			}
+			p.Patrol = append(p.Patrol, [2]int{x, y})
+		}
+	}
+	for _, name := range props.list("behaviors") {
+		p.Behaviors = append(p.Behaviors, actors.BehaviorDef{Name: name})
+	}
+	for name, dst := range map[string]**int{"thinkRate": &p.ThinkRate, "sight": &p.Sight, "patience": &p.Patience} {
+		if v, ok, err := props.int(name); err != nil {
+			return def, err
+		} else if ok {
+			*dst = &v
+		}
+	}
+
+	if stock, ok := props["shop"]; ok {
+		def.Shop = &ShopDef{
+			Name:     props["shopName"],
+			Greeting: props["greeting"],
+		}
+		for _, s := range strings.Split(stock, ";") {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+			parts := strings.Split(s, ":")
+			st := StockDef{Item: strings.TrimSpace(parts[0])}
+			if len(parts) > 1 {
+				if st.Price, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil {
+					return def, fmt.Errorf("shop: %w", err)
+				}
+			}
+			if len(parts) > 2 {
+				count, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+				if err != nil {
+					return def, fmt.Errorf("shop: %w", err)
+				}
+				st.Count = &count
+			}
+			def.Shop.Stock = append(def.Shop.Stock, st)
+		}
+	}
+
+	return def, nil
+}
@@ -0,0 +1,13 @@
+package settings
+
+// Volumes go from 0 to 1. Music and sound volumes are scaled by the master volume.
+var MasterVolume = 1.0
+var MusicVolume = 1.0
+var SoundVolume = 1.0
+
+var Muted bool
+var MusicMuted bool
+var SoundMuted bool
+
+// MusicDucking is how far music is turned down while big messages or combat reports are showing, from 0 for not at all to 1 for silence.
+var MusicDucking = 0.5
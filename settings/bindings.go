@@ -0,0 +1,181 @@
+package settings
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Action is something the player can do. Each is bound to any number of keys, mouse buttons and gamepad buttons.
+type Action string
+
+const (
+	ActionUp          Action = "up"
+	ActionDown        Action = "down"
+	ActionLeft        Action = "left"
+	ActionRight       Action = "right"
+	ActionConfirm     Action = "confirm"
+	ActionCancel      Action = "cancel"
+	ActionModifier    Action = "modifier" // Held to look instead of step.
+	ActionClick       Action = "click"
+	ActionAltClick    Action = "altClick"
+	ActionMap         Action = "map"
+	ActionInventory   Action = "inventory"
+	ActionOptions     Action = "options"
+	ActionCameraUp    Action = "cameraUp"
+	ActionCameraDown  Action = "cameraDown"
+	ActionCameraLeft  Action = "cameraLeft"
+	ActionCameraRight Action = "cameraRight"
+	ActionFilter      Action = "filter"
+	ActionShading     Action = "shading"
+	ActionObjectives  Action = "objectives"
+	ActionGlitch1     Action = "glitch1"
+	ActionGlitch2     Action = "glitch2"
+	ActionGlitch3     Action = "glitch3"
+	ActionGlitch4     Action = "glitch4"
+	ActionGlitch5     Action = "glitch5"
+	ActionGlitch6     Action = "glitch6"
+	ActionGlitch7     Action = "glitch7"
+	ActionGlitch8     Action = "glitch8"
+	ActionGlitch9     Action = "glitch9"
+)
+
+// GlitchActions select each glitch of the party, in order.
+var GlitchActions = []Action{
+	ActionGlitch1, ActionGlitch2, ActionGlitch3, ActionGlitch4, ActionGlitch5,
+	ActionGlitch6, ActionGlitch7, ActionGlitch8, ActionGlitch9,
+}
+
+// Actions is every action, in the order they are shown in the options.
+var Actions = []Action{
+	ActionUp, ActionDown, ActionLeft, ActionRight,
+	ActionConfirm, ActionCancel, ActionModifier,
+	ActionClick, ActionAltClick,
+	ActionMap, ActionInventory, ActionOptions,
+	ActionCameraUp, ActionCameraDown, ActionCameraLeft, ActionCameraRight,
+	ActionFilter, ActionShading,
+	ActionObjectives,
+	ActionGlitch1, ActionGlitch2, ActionGlitch3, ActionGlitch4, ActionGlitch5,
+	ActionGlitch6, ActionGlitch7, ActionGlitch8, ActionGlitch9,
+}
+
+// Binding is a single key, mouse button or standard layout gamepad button. Only one of them is set.
+type Binding struct {
+	Key   *ebiten.Key                   `json:"key,omitempty"`
+	Mouse *ebiten.MouseButton           `json:"mouse,omitempty"`
+	Pad   *ebiten.StandardGamepadButton `json:"pad,omitempty"`
+}
+
+func KeyBinding(k ebiten.Key) Binding {
+	return Binding{Key: &k}
+}
+
+func MouseBinding(b ebiten.MouseButton) Binding {
+	return Binding{Mouse: &b}
+}
+
+func PadBinding(b ebiten.StandardGamepadButton) Binding {
+	return Binding{Pad: &b}
+}
+
+// SameKind returns true if both bindings are keys, mouse buttons or gamepad buttons.
+func (b Binding) SameKind(o Binding) bool {
+	return (b.Key != nil) == (o.Key != nil) && (b.Mouse != nil) == (o.Mouse != nil) && (b.Pad != nil) == (o.Pad != nil)
+}
+
+var mouseNames = map[ebiten.MouseButton]string{
+	ebiten.MouseButtonLeft:   "LEFT",
+	ebiten.MouseButtonRight:  "RIGHT",
+	ebiten.MouseButtonMiddle: "MIDDLE",
+}
+
+var padNames = map[ebiten.StandardGamepadButton]string{
+	ebiten.StandardGamepadButtonRightBottom:      "A",
+	ebiten.StandardGamepadButtonRightRight:       "B",
+	ebiten.StandardGamepadButtonRightLeft:        "X",
+	ebiten.StandardGamepadButtonRightTop:         "Y",
+	ebiten.StandardGamepadButtonFrontTopLeft:     "LB",
+	ebiten.StandardGamepadButtonFrontTopRight:    "RB",
+	ebiten.StandardGamepadButtonFrontBottomLeft:  "LT",
+	ebiten.StandardGamepadButtonFrontBottomRight: "RT",
+	ebiten.StandardGamepadButtonCenterLeft:       "SELECT",
+	ebiten.StandardGamepadButtonCenterRight:      "START",
+	ebiten.StandardGamepadButtonLeftStick:        "LS",
+	ebiten.StandardGamepadButtonRightStick:       "RS",
+	ebiten.StandardGamepadButtonLeftTop:          "UP",
+	ebiten.StandardGamepadButtonLeftBottom:       "DOWN",
+	ebiten.StandardGamepadButtonLeftLeft:         "LEFT",
+	ebiten.StandardGamepadButtonLeftRight:        "RIGHT",
+	ebiten.StandardGamepadButtonCenterCenter:     "HOME",
+}
+
+func (b Binding) String() string {
+	switch {
+	case b.Key != nil:
+		return strings.ToUpper(b.Key.String())
+	case b.Mouse != nil:
+		if name, ok := mouseNames[*b.Mouse]; ok {
+			return "MOUSE " + name
+		}
+		return fmt.Sprintf("MOUSE %d", *b.Mouse)
+	case b.Pad != nil:
+		if name, ok := padNames[*b.Pad]; ok {
+			return "PAD " + name
+		}
+		return fmt.Sprintf("PAD %d", *b.Pad)
+	}
+	return "-"
+}
+
+// Bindings are what each action is bound to.
+var Bindings = DefaultBindings()
+
+// KeyRepeatDelay and KeyRepeatInterval are how many ticks a direction is held before it repeats, and then how often.
+var KeyRepeatDelay = 20
+var KeyRepeatInterval = 6
+
+// DefaultBindings returns the bindings the game ships with.
+func DefaultBindings() map[Action][]Binding {
+	return map[Action][]Binding{
+		ActionUp:          {KeyBinding(ebiten.KeyArrowUp), PadBinding(ebiten.StandardGamepadButtonLeftTop)},
+		ActionDown:        {KeyBinding(ebiten.KeyArrowDown), PadBinding(ebiten.StandardGamepadButtonLeftBottom)},
+		ActionLeft:        {KeyBinding(ebiten.KeyArrowLeft), PadBinding(ebiten.StandardGamepadButtonLeftLeft)},
+		ActionRight:       {KeyBinding(ebiten.KeyArrowRight), PadBinding(ebiten.StandardGamepadButtonLeftRight)},
+		ActionConfirm:     {KeyBinding(ebiten.KeyEnter), KeyBinding(ebiten.KeySpace), PadBinding(ebiten.StandardGamepadButtonRightBottom)},
+		ActionCancel:      {KeyBinding(ebiten.KeyEscape), PadBinding(ebiten.StandardGamepadButtonRightRight)},
+		ActionModifier:    {KeyBinding(ebiten.KeyShift), PadBinding(ebiten.StandardGamepadButtonFrontTopLeft)},
+		ActionClick:       {MouseBinding(ebiten.MouseButtonLeft)},
+		ActionAltClick:    {MouseBinding(ebiten.MouseButtonRight)},
+		ActionMap:         {KeyBinding(ebiten.KeyTab), PadBinding(ebiten.StandardGamepadButtonCenterLeft)},
+		ActionInventory:   {KeyBinding(ebiten.KeyI), PadBinding(ebiten.StandardGamepadButtonRightTop)},
+		ActionOptions:     {KeyBinding(ebiten.KeyO), PadBinding(ebiten.StandardGamepadButtonCenterRight)},
+		ActionCameraUp:    {KeyBinding(ebiten.KeyW)},
+		ActionCameraDown:  {KeyBinding(ebiten.KeyS)},
+		ActionCameraLeft:  {KeyBinding(ebiten.KeyA)},
+		ActionCameraRight: {KeyBinding(ebiten.KeyD)},
+		ActionFilter:      {KeyBinding(ebiten.KeyM)},
+		ActionShading:     {KeyBinding(ebiten.KeyN)},
+		ActionObjectives:  {KeyBinding(ebiten.KeyJ), PadBinding(ebiten.StandardGamepadButtonRightLeft)},
+		ActionGlitch1:     {KeyBinding(ebiten.KeyDigit1)},
+		ActionGlitch2:     {KeyBinding(ebiten.KeyDigit2)},
+		ActionGlitch3:     {KeyBinding(ebiten.KeyDigit3)},
+		ActionGlitch4:     {KeyBinding(ebiten.KeyDigit4)},
+		ActionGlitch5:     {KeyBinding(ebiten.KeyDigit5)},
+		ActionGlitch6:     {KeyBinding(ebiten.KeyDigit6)},
+		ActionGlitch7:     {KeyBinding(ebiten.KeyDigit7)},
+		ActionGlitch8:     {KeyBinding(ebiten.KeyDigit8)},
+		ActionGlitch9:     {KeyBinding(ebiten.KeyDigit9)},
+	}
+}
+
+// Bind binds the action to b, replacing whatever key, mouse button or gamepad button it was bound to before.
+func Bind(a Action, b Binding) {
+	bindings := []Binding{}
+	for _, o := range Bindings[a] {
+		if !o.SameKind(b) {
+			bindings = append(bindings, o)
+		}
+	}
+	Bindings[a] = append(bindings, b)
+}
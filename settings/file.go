@@ -0,0 +1,113 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// file is how settings are saved to disk. Anything missing from a saved file keeps its default.
+type file struct {
+	Filter            string               `json:"filter"` // "clarity" or "mayo"
+	StackShading      bool                 `json:"stackShading"`
+	PartyTrail        int                  `json:"partyTrail"`
+	MasterVolume      float64              `json:"masterVolume"`
+	MusicVolume       float64              `json:"musicVolume"`
+	SoundVolume       float64              `json:"soundVolume"`
+	Muted             bool                 `json:"muted"`
+	MusicMuted        bool                 `json:"musicMuted"`
+	SoundMuted        bool                 `json:"soundMuted"`
+	MusicDucking      float64              `json:"musicDucking"`
+	KeyRepeatDelay    int                  `json:"keyRepeatDelay"`
+	KeyRepeatInterval int                  `json:"keyRepeatInterval"`
+	Bindings          map[Action][]Binding `json:"bindings"`
+}
+
+// Path returns where settings are saved.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "haven", "settings.json"), nil
+}
+
+// Load reads saved settings, if there are any.
+func Load() error {
+	p, err := Path()
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	f := current()
+	if err := json.Unmarshal(b, &f); err != nil {
+		return err
+	}
+	if f.Filter == "mayo" {
+		FilterMode = MayoMode
+	} else {
+		FilterMode = ClarityMode
+	}
+	StackShading = f.StackShading
+	PartyTrail = f.PartyTrail
+	MasterVolume = f.MasterVolume
+	MusicVolume = f.MusicVolume
+	SoundVolume = f.SoundVolume
+	Muted = f.Muted
+	MusicMuted = f.MusicMuted
+	SoundMuted = f.SoundMuted
+	MusicDucking = f.MusicDucking
+	KeyRepeatDelay = f.KeyRepeatDelay
+	KeyRepeatInterval = f.KeyRepeatInterval
+	Bindings = f.Bindings
+	return nil
+}
+
+// Save writes the current settings.
+func Save() error {
+	p, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(current(), "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, b, 0o644)
+}
+
+func current() file {
+	f := file{
+		Filter:            "clarity",
+		StackShading:      StackShading,
+		PartyTrail:        PartyTrail,
+		MasterVolume:      MasterVolume,
+		MusicVolume:       MusicVolume,
+		SoundVolume:       SoundVolume,
+		Muted:             Muted,
+		MusicMuted:        MusicMuted,
+		SoundMuted:        SoundMuted,
+		MusicDucking:      MusicDucking,
+		KeyRepeatDelay:    KeyRepeatDelay,
+		KeyRepeatInterval: KeyRepeatInterval,
+		Bindings:          make(map[Action][]Binding),
+	}
+	if FilterMode == MayoMode {
+		f.Filter = "mayo"
+	}
+	for a, b := range Bindings {
+		f.Bindings[a] = b
+	}
+	return f
+}
@@ -0,0 +1,4 @@
+package settings
+
+// PartyTrail is how many of the player's glitches trail behind it in the overworld. -1 is the whole party and 0 is none.
+var PartyTrail = -1
@@ -8,11 +8,15 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/kettek/ebihack23/game"
 	"github.com/kettek/ebihack23/inputs"
+	"github.com/kettek/ebihack23/replay"
 	"github.com/kettek/ebihack23/res"
 	"github.com/kettek/ebihack23/rooms"
 	"github.com/kettek/ebihack23/settings"
 )
 
+// StartRoom is the room a new game begins in.
+const StartRoom = "000_spawn"
+
 type Game struct {
 	world            *game.World
 	cursorX, cursorY int
@@ -21,7 +25,9 @@ type Game struct {
 	processChan      chan func() bool
 	Cheats           bool
 	cheatEngine      *CheatEngine
-	keys             []ebiten.Key
+	Recorder         *replay.Recorder // Records the game's inputs, if set before entering.
+	Playback         *replay.Player   // Plays back a recording instead of taking inputs, if set before entering.
+	err              error            // Why the world couldn't be made, returned from Update.
 }
 
 func NewGame() *Game {
@@ -29,7 +35,6 @@ func NewGame() *Game {
 		processChan: make(chan func() bool),
 		Cheats:      true,
 		cheatEngine: NewCheatEngine(),
-		keys:        make([]ebiten.Key, 60),
 	}
 	g.cheatEngine.AddCheat("NOCLIP", func(g *Game) {
 		if g.world.PlayerActor != nil {
@@ -57,10 +62,23 @@ func NewGame() *Game {
 			fmt.Println("talk to me, baby")
 		}
 	})
+	g.cheatEngine.AddCheat("FAST", func(g *Game) {
+		if g.world.Clock.Speed() == 1 {
+			g.world.Clock.SetSpeed(4)
+			fmt.Println("gotta go fast")
+		} else {
+			g.world.Clock.SetSpeed(1)
+			fmt.Println("slow and steady")
+		}
+	})
 	warpTo := func(r string) {
 		fmt.Printf("warping to \"%s\", you dirty cheater\n", r)
 		g.world.Room.RemoveActor(g.world.PlayerActor)
-		room := rooms.GetRoom(r)
+		room, err := g.world.GetRoom(r)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		room.AddActor(g.world.PlayerActor)
 		g.world.PlayerActor.SetPosition(1, 1, 0)
 		g.world.EnterRoom(room)
@@ -97,53 +115,34 @@ func (g *Game) Room() *game.Room {
 }
 
 func (g *Game) Update() error {
-	if g.Cheats {
+	if g.err != nil {
+		return g.err
+	}
+	// Cheats change the world outside of its inputs, so they'd throw off a recording.
+	if g.Cheats && g.Recorder == nil && g.Playback == nil {
 		g.cheatEngine.Update(g)
 	}
 	res.UpdateSounds()
 	res.Jukebox.Update()
-	// Get inputs.
-	if inpututil.IsKeyJustReleased(ebiten.KeyEscape) {
-		g.world.Input(inputs.Cancel{})
-	}
-	if inpututil.IsKeyJustReleased(ebiten.KeyEnter) || inpututil.IsKeyJustReleased(ebiten.KeySpace) {
-		g.world.Input(inputs.Confirm{})
-	}
-	{
-		var x int
-		var y int
-		if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
-			y--
-		}
-		if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
-			y++
-		}
-		if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
-			x--
-		}
-		if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
-			x++
-		}
-		if x != 0 || y != 0 {
-			g.world.Input(inputs.Direction{X: x, Y: y, Mod: ebiten.IsKeyPressed(ebiten.KeyShift)})
-		}
-	}
-	{
-		if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
-			x, y := ebiten.CursorPosition()
-			g.world.Input(inputs.Click{X: float64(x), Y: float64(y), Which: ebiten.MouseButtonLeft, Mod: ebiten.IsKeyPressed(ebiten.KeyShift)})
-		} else if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonRight) {
-			x, y := ebiten.CursorPosition()
-			g.world.Input(inputs.Click{X: float64(x), Y: float64(y), Which: ebiten.MouseButtonRight, Mod: ebiten.IsKeyPressed(ebiten.KeyShift)})
+
+	if g.Playback != nil {
+		g.Playback.Update(g.world)
+		if g.Playback.Done(g.world) {
+			if rec := g.Playback.Recording(); rec.Result != nil {
+				if err := replay.Check(g.world, *rec.Result); err != nil {
+					fmt.Println(err)
+				} else {
+					fmt.Println("replay finished where it was recorded")
+				}
+			}
+			g.Playback = nil
 		}
+		return nil
 	}
-	// Also send any pressed keys as inputs.
-	{
-		g.keys = g.keys[:0]
-		g.keys = inpututil.AppendJustReleasedKeys(g.keys)
-		for _, k := range g.keys {
-			g.world.Input(inputs.Key{Key: k})
-		}
+
+	// Get inputs.
+	for _, in := range inputs.Actions.Inputs() {
+		g.world.Input(in)
 	}
 
 	g.world.Update()
@@ -171,7 +170,7 @@ func (g *Game) Update() error {
 			g.hoveredActor.Hover(false)
 			g.hoveredActor = nil
 		}
-		if px >= 0 && px < rw && py >= 0 && py < rh {
+		if px >= 0 && px < rw && py >= 0 && py < rh && !game.OverMinimap(cx, cy) {
 			g.cursorX, g.cursorY = px, py
 			if tile := g.Room().GetTile(px, py); tile != nil {
 				if tile.SpriteStack != nil {
@@ -191,16 +190,17 @@ func (g *Game) Update() error {
 	if g.cursorX != -1 && g.cursorY != -1 {
 		lmb := false
 		rmb := false
-		if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
+		if inputs.Actions.JustReleased(settings.ActionClick) {
 			lmb = true
 		}
-		if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonRight) {
+		if inputs.Actions.JustReleased(settings.ActionAltClick) {
 			rmb = true
 		}
+		mod := inputs.Actions.Pressed(settings.ActionModifier)
 		if lmb {
-			g.world.Input(inputs.MapClick{X: g.cursorX, Y: g.cursorY, Which: ebiten.MouseButtonLeft, Mod: ebiten.IsKeyPressed(ebiten.KeyShift)})
+			g.world.Input(inputs.MapClick{X: g.cursorX, Y: g.cursorY, Which: ebiten.MouseButtonLeft, Mod: mod})
 		} else if rmb {
-			g.world.Input(inputs.MapClick{X: g.cursorX, Y: g.cursorY, Which: ebiten.MouseButtonRight, Mod: ebiten.IsKeyPressed(ebiten.KeyShift)})
+			g.world.Input(inputs.MapClick{X: g.cursorX, Y: g.cursorY, Which: ebiten.MouseButtonRight, Mod: mod})
 		}
 	}
 
@@ -213,30 +213,56 @@ func (g *Game) Update() error {
 		x, y := g.Room().Center()
 		g.Camera().MoveTo(x, y)
 	}*/
-	if inpututil.IsKeyJustReleased(ebiten.KeyM) {
+	if inputs.Actions.JustReleased(settings.ActionFilter) {
 		if settings.FilterMode == settings.MayoMode {
 			settings.FilterMode = settings.ClarityMode
 		} else {
 			settings.FilterMode = settings.MayoMode
 		}
 	}
-	if inpututil.IsKeyJustReleased(ebiten.KeyN) {
+	if inputs.Actions.JustReleased(settings.ActionShading) {
 		settings.StackShading = !settings.StackShading
 	}
+	if inputs.Actions.JustReleased(settings.ActionMap) && g.world.Combat == nil && len(g.world.Prompts) == 0 {
+		NextState(NewWorldMap(g))
+	}
+	if g.world.WantsGlitchdex() {
+		NextState(NewGlitchdex(g))
+	}
+	if inputs.Actions.JustReleased(settings.ActionInventory) && g.world.Combat == nil && len(g.world.Prompts) == 0 && g.world.PlayerActor != nil {
+		NextState(NewInventory(g))
+	}
+	if inputs.Actions.JustReleased(settings.ActionOptions) && g.world.Combat == nil && len(g.world.Prompts) == 0 {
+		NextState(NewOptions(g))
+	}
 
 	return nil
 }
 
 func (g *Game) Enter() {
 	if g.world == nil {
-		g.world = game.NewWorld(rooms.GetRoom)
-		g.world.EnterRoom(rooms.GetRoom("000_spawn"))
+		switch {
+		case g.Playback != nil:
+			g.world, g.err = g.Playback.World()
+		case g.Recorder != nil:
+			g.world = g.Recorder.World()
+		default:
+			w := game.NewWorld(rooms.GetRoom)
+			var room *game.Room
+			if room, g.err = w.GetRoom(StartRoom); g.err == nil {
+				w.EnterRoom(room)
+				g.world = w
+			}
+		}
 	}
 }
 func (g *Game) Leave() {
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
+	if g.world == nil {
+		return
+	}
 	g.world.Draw(screen)
 }
 
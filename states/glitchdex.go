@@ -0,0 +1,202 @@
+package states
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/inputs"
+	"github.com/kettek/ebihack23/res"
+	"github.com/kettek/ebihack23/settings"
+	"github.com/tinne26/etxt"
+)
+
+const glitchdexWidth = 480
+const glitchdexHeight = 300
+const glitchdexListWidth = 160
+const glitchdexRowHeight = 16
+
+// Glitchdex is an overlay listing every glitch species the player has come across. More is shown about a species once it has been fought and captured.
+type Glitchdex struct {
+	game     *Game
+	entries  []*game.GlitchdexEntry
+	selected int
+	stack    *game.SpriteStack // Of the selected entry.
+	rowsX    int
+	rowsY    int
+}
+
+func NewGlitchdex(g *Game) *Glitchdex {
+	return &Glitchdex{
+		game: g,
+	}
+}
+
+func (s *Glitchdex) Enter() {
+	s.entries = s.game.world.Glitchdex.Entries()
+	s.selected = 0
+	s.selectEntry(0)
+}
+
+func (s *Glitchdex) Leave() {
+}
+
+func (s *Glitchdex) selectEntry(i int) {
+	if i < 0 || i >= len(s.entries) {
+		s.stack = nil
+		return
+	}
+	s.selected = i
+	s.stack = nil
+	if s.entries[i].Sprite != "" {
+		s.stack = game.NewSpriteStack(s.entries[i].Sprite)
+		s.stack.Shaded = true
+	}
+}
+
+func (s *Glitchdex) Update() error {
+	res.UpdateSounds()
+	res.Jukebox.Update()
+	if inputs.Actions.JustReleased(settings.ActionCancel) || inputs.Actions.JustReleased(settings.ActionAltClick) {
+		NextState(s.game)
+		return nil
+	}
+	if inputs.Actions.Repeated(settings.ActionUp) && s.selected > 0 {
+		s.selectEntry(s.selected - 1)
+		res.PlaySound("button")
+	}
+	if inputs.Actions.Repeated(settings.ActionDown) && s.selected < len(s.entries)-1 {
+		s.selectEntry(s.selected + 1)
+		res.PlaySound("button")
+	}
+	if inputs.Actions.JustReleased(settings.ActionClick) {
+		x, y := ebiten.CursorPosition()
+		i := (y - s.rowsY) / glitchdexRowHeight
+		if x >= s.rowsX && x <= s.rowsX+glitchdexListWidth && y >= s.rowsY && i < len(s.entries) && i != s.selected {
+			s.selectEntry(i)
+			res.PlaySound("button")
+		}
+	}
+	if s.stack != nil {
+		s.stack.Rotation += 0.02
+	}
+	return nil
+}
+
+func (s *Glitchdex) Draw(screen *ebiten.Image) {
+	s.game.Draw(screen)
+
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	vector.DrawFilledRect(screen, 0, 0, float32(sw), float32(sh), color.NRGBA{0, 0, 0, 150}, false)
+
+	x := sw/2 - glitchdexWidth/2
+	y := sh/2 - glitchdexHeight/2
+	vector.DrawFilledRect(screen, float32(x), float32(y), glitchdexWidth, glitchdexHeight, color.NRGBA{19, 19, 97, 230}, false)
+	vector.StrokeRect(screen, float32(x), float32(y), glitchdexWidth, glitchdexHeight, 3, color.NRGBA{194, 193, 174, 255}, true)
+	vector.StrokeLine(screen, float32(x+glitchdexListWidth), float32(y+24), float32(x+glitchdexListWidth), float32(y+glitchdexHeight-6), 1, color.NRGBA{194, 193, 174, 255}, false)
+
+	res.Text.Utils().StoreState()
+	res.Text.SetFont(res.DefFont.Font)
+	res.Text.SetSize(float64(res.DefFont.Size))
+	res.Text.SetAlign(etxt.Top | etxt.Left)
+	res.Text.SetColor(color.NRGBA{245, 245, 220, 255})
+	res.Text.Draw(screen, "GLITCHDEX", x+8, y+6)
+	seen, captured := s.game.world.Glitchdex.Count()
+	res.Text.SetAlign(etxt.Top | etxt.Right)
+	res.Text.SetColor(color.NRGBA{50, 200, 255, 255})
+	res.Text.Draw(screen, fmt.Sprintf("SEEN %d CAUGHT %d", seen, captured), x+glitchdexWidth-8, y+6)
+	res.Text.SetAlign(etxt.Top | etxt.Left)
+
+	s.rowsX = x
+	s.rowsY = y + 30
+	if len(s.entries) == 0 {
+		res.Text.SetColor(color.NRGBA{128, 128, 128, 255})
+		res.Text.Draw(screen, "nothing, yet", x+8, s.rowsY)
+	}
+	for i, e := range s.entries {
+		text := e.Species
+		if e.Captured {
+			text += " *"
+		}
+		if i == s.selected {
+			text = "> " + text
+			res.Text.SetColor(color.NRGBA{255, 255, 50, 255})
+		} else {
+			text = "  " + text
+			res.Text.SetColor(color.NRGBA{255, 255, 255, 255})
+		}
+		res.Text.Draw(screen, text, x+8, s.rowsY+i*glitchdexRowHeight)
+	}
+
+	if s.selected < len(s.entries) {
+		e := s.entries[s.selected]
+		dx := x + glitchdexListWidth + 8
+		dy := y + 30
+
+		if s.stack != nil {
+			geom := ebiten.GeoM{}
+			geom.Scale(8, 8)
+			geom.Translate(float64(x+glitchdexWidth-110), float64(dy+20))
+			s.stack.DrawIso(screen, geom)
+		}
+
+		res.Text.SetColor(color.NRGBA{245, 245, 220, 255})
+		res.Text.Draw(screen, e.Species, dx, dy)
+		dy += 20
+
+		res.Text.SetFont(res.SmallFont.Font)
+		res.Text.SetSize(float64(res.SmallFont.Size))
+		status := "SEEN"
+		if e.Captured {
+			status = "CAPTURED"
+		} else if e.Fought {
+			status = "FOUGHT"
+		}
+		res.Text.SetColor(color.NRGBA{50, 200, 255, 255})
+		res.Text.Draw(screen, status, dx, dy)
+		dy += 18
+
+		// Stats are learned by fighting one.
+		if e.Fought || e.Captured {
+			res.Text.SetColor(color.NRGBA{128, 128, 0, 255})
+			res.Text.Draw(screen, fmt.Sprintf("LVL %d", e.Level), dx, dy)
+			dy += 12
+			res.Text.SetColor(color.NRGBA{50, 255, 50, 200})
+			res.Text.Draw(screen, fmt.Sprintf("INTEGRITY %d", e.Stats[2]), dx, dy)
+			dy += 12
+			res.Text.SetColor(color.NRGBA{255, 50, 50, 200})
+			res.Text.Draw(screen, fmt.Sprintf("FIREWALL %d", e.Stats[1]), dx, dy)
+			dy += 12
+			res.Text.SetColor(color.NRGBA{255, 255, 50, 200})
+			res.Text.Draw(screen, fmt.Sprintf("PENETRATION %d", e.Stats[0]), dx, dy)
+		} else {
+			res.Text.SetColor(color.NRGBA{128, 128, 128, 255})
+			res.Text.Draw(screen, "stats: fight one to learn", dx, dy)
+		}
+		dy += 20
+
+		// And abilities by capturing one.
+		res.Text.SetColor(color.NRGBA{194, 193, 174, 255})
+		if !e.Captured {
+			res.Text.SetColor(color.NRGBA{128, 128, 128, 255})
+			res.Text.Draw(screen, "ABILITY: capture one to learn", dx, dy)
+			dy += 12
+		} else if e.Ability == "" {
+			res.Text.Draw(screen, "ABILITY: -", dx, dy)
+			dy += 12
+		} else {
+			res.Text.Draw(screen, "ABILITY: "+e.Ability, dx, dy)
+			dy += 12
+			res.Text.DrawWithWrap(screen, string(game.AbilityDescriptions[game.AbilityType(e.Ability)]), dx, dy, glitchdexWidth-glitchdexListWidth-130)
+			dy += 36
+		}
+		dy += 8
+
+		res.Text.SetColor(color.NRGBA{194, 193, 174, 255})
+		res.Text.DrawWithWrap(screen, "FOUND IN: "+strings.Join(e.Rooms, ", "), dx, dy, glitchdexWidth-glitchdexListWidth-16)
+	}
+	res.Text.Utils().RestoreState()
+}
@@ -0,0 +1,166 @@
+package states
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/kettek/ebihack23/game"
+	"github.com/kettek/ebihack23/inputs"
+	"github.com/kettek/ebihack23/res"
+	"github.com/kettek/ebihack23/settings"
+	"github.com/tinne26/etxt"
+)
+
+const inventoryWidth = 360
+const inventoryHeight = 240
+const inventoryRowHeight = 20
+
+// Inventory is an overlay for looking at and using the player's items outside of combat.
+type Inventory struct {
+	game     *Game
+	selected int
+	message  string
+	rowsY    int // Where the first row was drawn, for clicking.
+	rowsX    int
+}
+
+func NewInventory(g *Game) *Inventory {
+	return &Inventory{
+		game: g,
+	}
+}
+
+func (s *Inventory) Enter() {
+	s.selected = 0
+	s.message = ""
+}
+
+func (s *Inventory) Leave() {
+}
+
+func (s *Inventory) inventory() *game.Inventory {
+	if holder, ok := s.game.world.PlayerActor.(game.ItemHolder); ok {
+		return holder.Inventory()
+	}
+	return &game.Inventory{}
+}
+
+func (s *Inventory) use() {
+	inv := s.inventory()
+	if s.selected < 0 || s.selected >= len(inv.Items) {
+		return
+	}
+	t := inv.Items[s.selected].Type
+	if !game.ItemUsableInField(t) {
+		if game.ItemUsableInCombat(t) {
+			s.message = fmt.Sprintf("%s can only be used in combat", t)
+		} else {
+			s.message = fmt.Sprintf("%s can't be used from here", t)
+		}
+		res.PlaySound("bump")
+		return
+	}
+	text, ok := s.game.world.UseItem(t)
+	s.message = text
+	if !ok {
+		res.PlaySound("miss")
+		return
+	}
+	res.PlaySound("boost")
+	if s.selected >= len(inv.Items) {
+		s.selected = len(inv.Items) - 1
+	}
+}
+
+func (s *Inventory) Update() error {
+	res.UpdateSounds()
+	res.Jukebox.Update()
+	if inputs.Actions.JustReleased(settings.ActionInventory) || inputs.Actions.JustReleased(settings.ActionCancel) || inputs.Actions.JustReleased(settings.ActionAltClick) {
+		NextState(s.game)
+		return nil
+	}
+	count := len(s.inventory().Items)
+	if inputs.Actions.Repeated(settings.ActionUp) && s.selected > 0 {
+		s.selected--
+		res.PlaySound("button")
+	}
+	if inputs.Actions.Repeated(settings.ActionDown) && s.selected < count-1 {
+		s.selected++
+		res.PlaySound("button")
+	}
+	if inputs.Actions.JustReleased(settings.ActionConfirm) {
+		s.use()
+	}
+	if inputs.Actions.JustReleased(settings.ActionClick) {
+		x, y := ebiten.CursorPosition()
+		i := (y - s.rowsY) / inventoryRowHeight
+		if x >= s.rowsX && x <= s.rowsX+inventoryWidth && y >= s.rowsY && i < count {
+			s.selected = i
+			s.use()
+		}
+	}
+	return nil
+}
+
+func (s *Inventory) Draw(screen *ebiten.Image) {
+	s.game.Draw(screen)
+
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	vector.DrawFilledRect(screen, 0, 0, float32(sw), float32(sh), color.NRGBA{0, 0, 0, 150}, false)
+
+	x := sw/2 - inventoryWidth/2
+	y := sh/2 - inventoryHeight/2
+	vector.DrawFilledRect(screen, float32(x), float32(y), inventoryWidth, inventoryHeight, color.NRGBA{19, 19, 97, 230}, false)
+	vector.StrokeRect(screen, float32(x), float32(y), inventoryWidth, inventoryHeight, 3, color.NRGBA{194, 193, 174, 255}, true)
+
+	res.Text.Utils().StoreState()
+	res.Text.SetFont(res.DefFont.Font)
+	res.Text.SetSize(float64(res.DefFont.Size))
+	res.Text.SetAlign(etxt.Top | etxt.Left)
+	res.Text.SetColor(color.NRGBA{245, 245, 220, 255})
+	res.Text.Draw(screen, "ITEMS", x+8, y+6)
+	res.Text.SetAlign(etxt.Top | etxt.Right)
+	res.Text.SetColor(color.NRGBA{50, 200, 255, 255})
+	res.Text.Draw(screen, fmt.Sprintf("CYCLES %d", s.inventory().Cycles), x+inventoryWidth-8, y+6)
+	res.Text.SetAlign(etxt.Top | etxt.Left)
+
+	s.rowsX = x
+	s.rowsY = y + 30
+	inv := s.inventory()
+	if inv.Empty() {
+		res.Text.SetColor(color.NRGBA{128, 128, 128, 255})
+		res.Text.Draw(screen, "nothing", x+8, s.rowsY)
+	}
+	for i, item := range inv.Items {
+		ry := s.rowsY + i*inventoryRowHeight
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(x+8), float64(ry))
+		screen.DrawImage(res.LoadImage("icon-item"), op)
+		text := fmt.Sprintf("%s x%d", item.Type, item.Count)
+		if i == s.selected {
+			text = "> " + text
+			res.Text.SetColor(color.NRGBA{255, 255, 50, 255})
+		} else {
+			text = "  " + text
+			res.Text.SetColor(color.NRGBA{255, 255, 255, 255})
+		}
+		if !game.ItemUsableInField(item.Type) {
+			res.Text.SetColor(color.NRGBA{128, 128, 128, 255})
+		}
+		res.Text.Draw(screen, text, x+28, ry)
+	}
+
+	res.Text.SetFont(res.SmallFont.Font)
+	res.Text.SetSize(float64(res.SmallFont.Size))
+	if s.selected >= 0 && s.selected < len(inv.Items) {
+		res.Text.SetColor(color.NRGBA{194, 193, 174, 255})
+		res.Text.DrawWithWrap(screen, string(game.ItemDescriptions[inv.Items[s.selected].Type]), x+8, y+inventoryHeight-48, inventoryWidth-16)
+	}
+	if s.message != "" {
+		res.Text.SetColor(color.NRGBA{50, 200, 255, 255})
+		res.Text.DrawWithWrap(screen, s.message, x+8, y+inventoryHeight-24, inventoryWidth-16)
+	}
+	res.Text.Utils().RestoreState()
+}
@@ -0,0 +1,269 @@
+package states
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/kettek/ebihack23/inputs"
+	"github.com/kettek/ebihack23/res"
+	"github.com/kettek/ebihack23/settings"
+	"github.com/tinne26/etxt"
+)
+
+const optionsWidth = 440
+const optionsRowHeight = 16
+const optionsVisibleRows = 24 // The rest are scrolled to.
+const optionsHeight = 30 + optionsVisibleRows*optionsRowHeight + 14
+const optionsLabelWidth = 140
+
+var actionLabels = map[settings.Action]string{
+	settings.ActionUp:          "UP",
+	settings.ActionDown:        "DOWN",
+	settings.ActionLeft:        "LEFT",
+	settings.ActionRight:       "RIGHT",
+	settings.ActionConfirm:     "CONFIRM",
+	settings.ActionCancel:      "CANCEL",
+	settings.ActionModifier:    "LOOK (HOLD)",
+	settings.ActionClick:       "CLICK",
+	settings.ActionAltClick:    "ALT CLICK",
+	settings.ActionMap:         "MAP",
+	settings.ActionInventory:   "ITEMS",
+	settings.ActionOptions:     "OPTIONS",
+	settings.ActionCameraUp:    "CAMERA UP",
+	settings.ActionCameraDown:  "CAMERA DOWN",
+	settings.ActionCameraLeft:  "CAMERA LEFT",
+	settings.ActionCameraRight: "CAMERA RIGHT",
+	settings.ActionFilter:      "FILTER",
+	settings.ActionShading:     "SHADING",
+	settings.ActionObjectives:  "OBJECTIVES",
+	settings.ActionGlitch1:     "GLITCH 1",
+	settings.ActionGlitch2:     "GLITCH 2",
+	settings.ActionGlitch3:     "GLITCH 3",
+	settings.ActionGlitch4:     "GLITCH 4",
+	settings.ActionGlitch5:     "GLITCH 5",
+	settings.ActionGlitch6:     "GLITCH 6",
+	settings.ActionGlitch7:     "GLITCH 7",
+	settings.ActionGlitch8:     "GLITCH 8",
+	settings.ActionGlitch9:     "GLITCH 9",
+}
+
+// audioOption is a row of the options for a volume, along with its mute if it has one.
+type audioOption struct {
+	label  string
+	volume *float64
+	muted  *bool
+}
+
+var audioOptions = []audioOption{
+	{"MASTER", &settings.MasterVolume, &settings.Muted},
+	{"MUSIC", &settings.MusicVolume, &settings.MusicMuted},
+	{"SOUNDS", &settings.SoundVolume, &settings.SoundMuted},
+	{"MUSIC DUCKING", &settings.MusicDucking, nil},
+}
+
+// volumeStep is how much a volume changes with each left or right.
+const volumeStep = 0.1
+
+// Options is an overlay for audio and controls. Volumes are changed with left and right, and picked to mute them. Picking an action waits for the next key, mouse button or gamepad button, which replaces whatever of that kind the action was bound to. Settings are saved on the way out.
+type Options struct {
+	game      *Game
+	selected  int // Audio rows come first, then actions, then the reset row.
+	top       int // First row shown.
+	capturing bool
+	rowsX     int
+	rowsY     int
+}
+
+func NewOptions(g *Game) *Options {
+	return &Options{
+		game: g,
+	}
+}
+
+func (s *Options) Enter() {
+	s.selected = 0
+	s.top = 0
+	s.capturing = false
+}
+
+func (s *Options) Leave() {
+	if err := settings.Save(); err != nil {
+		fmt.Println("couldn't save settings:", err)
+	}
+}
+
+// rows returns how many rows there are, including the reset row.
+func (s *Options) rows() int {
+	return len(audioOptions) + len(settings.Actions) + 1
+}
+
+// action returns the action of the selected row, if it is one.
+func (s *Options) action() (settings.Action, bool) {
+	i := s.selected - len(audioOptions)
+	if i < 0 || i >= len(settings.Actions) {
+		return "", false
+	}
+	return settings.Actions[i], true
+}
+
+func (s *Options) pick() {
+	if s.selected < len(audioOptions) {
+		if o := audioOptions[s.selected]; o.muted != nil {
+			*o.muted = !*o.muted
+		}
+		res.PlaySound("button")
+		return
+	}
+	if _, ok := s.action(); !ok {
+		settings.Bindings = settings.DefaultBindings()
+		res.PlaySound("poweron")
+		return
+	}
+	s.capturing = true
+	res.PlaySound("button")
+}
+
+// adjust changes the selected volume by the given number of steps.
+func (s *Options) adjust(steps int) {
+	if s.selected >= len(audioOptions) {
+		return
+	}
+	v := audioOptions[s.selected].volume
+	*v = math.Round((*v+float64(steps)*volumeStep)*10) / 10
+	*v = math.Min(1, math.Max(0, *v))
+	res.PlaySound("button")
+}
+
+func (s *Options) Update() error {
+	res.UpdateSounds()
+	res.Jukebox.Update()
+
+	if s.capturing {
+		if inpututil.IsKeyJustReleased(ebiten.KeyEscape) {
+			s.capturing = false
+			res.PlaySound("miss")
+			return nil
+		}
+		if b, ok := inputs.Actions.Capture(); ok {
+			a, _ := s.action()
+			settings.Bind(a, b)
+			s.capturing = false
+			res.PlaySound("boost")
+		}
+		return nil
+	}
+
+	if inputs.Actions.JustReleased(settings.ActionCancel) || inputs.Actions.JustReleased(settings.ActionAltClick) || inputs.Actions.JustReleased(settings.ActionOptions) {
+		NextState(s.game)
+		return nil
+	}
+	if inputs.Actions.Repeated(settings.ActionUp) && s.selected > 0 {
+		s.selected--
+		res.PlaySound("button")
+	}
+	if inputs.Actions.Repeated(settings.ActionDown) && s.selected < s.rows()-1 {
+		s.selected++
+		res.PlaySound("button")
+	}
+	if s.selected < s.top {
+		s.top = s.selected
+	} else if s.selected >= s.top+optionsVisibleRows {
+		s.top = s.selected - optionsVisibleRows + 1
+	}
+	if inputs.Actions.Repeated(settings.ActionLeft) {
+		s.adjust(-1)
+	}
+	if inputs.Actions.Repeated(settings.ActionRight) {
+		s.adjust(1)
+	}
+	if inputs.Actions.JustReleased(settings.ActionConfirm) {
+		s.pick()
+	}
+	if inputs.Actions.JustReleased(settings.ActionClick) {
+		x, y := ebiten.CursorPosition()
+		i := (y - s.rowsY) / optionsRowHeight
+		if x >= s.rowsX && x <= s.rowsX+optionsWidth && y >= s.rowsY && i < optionsVisibleRows && s.top+i < s.rows() {
+			s.selected = s.top + i
+			s.pick()
+		}
+	}
+	return nil
+}
+
+// row returns the label and value of the given row.
+func (s *Options) row(i int) (string, string) {
+	if i < len(audioOptions) {
+		o := audioOptions[i]
+		if o.muted != nil && *o.muted {
+			return o.label, "< MUTED >"
+		}
+		return o.label, fmt.Sprintf("< %3d%% >", int(math.Round(*o.volume*100)))
+	}
+	if i == s.rows()-1 {
+		return "RESET CONTROLS", ""
+	}
+	a := settings.Actions[i-len(audioOptions)]
+	label := actionLabels[a]
+	if label == "" {
+		label = strings.ToUpper(string(a))
+	}
+	var bound []string
+	for _, b := range settings.Bindings[a] {
+		bound = append(bound, b.String())
+	}
+	text := strings.Join(bound, ", ")
+	if text == "" {
+		text = "-"
+	}
+	return label, text
+}
+
+func (s *Options) Draw(screen *ebiten.Image) {
+	s.game.Draw(screen)
+
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	vector.DrawFilledRect(screen, 0, 0, float32(sw), float32(sh), color.NRGBA{0, 0, 0, 150}, false)
+
+	x := sw/2 - optionsWidth/2
+	y := sh/2 - optionsHeight/2
+	vector.DrawFilledRect(screen, float32(x), float32(y), optionsWidth, optionsHeight, color.NRGBA{19, 19, 97, 230}, false)
+	vector.StrokeRect(screen, float32(x), float32(y), optionsWidth, optionsHeight, 3, color.NRGBA{194, 193, 174, 255}, true)
+
+	res.Text.Utils().StoreState()
+	res.Text.SetFont(res.DefFont.Font)
+	res.Text.SetSize(float64(res.DefFont.Size))
+	res.Text.SetAlign(etxt.Top | etxt.Left)
+	res.Text.SetColor(color.NRGBA{245, 245, 220, 255})
+	res.Text.Draw(screen, "OPTIONS", x+8, y+6)
+
+	s.rowsX = x
+	s.rowsY = y + 30
+	res.Text.SetFont(res.SmallFont.Font)
+	res.Text.SetSize(float64(res.SmallFont.Size))
+	for i := s.top; i < s.rows() && i < s.top+optionsVisibleRows; i++ {
+		label, text := s.row(i)
+		if i == s.selected {
+			label = "> " + label
+			res.Text.SetColor(color.NRGBA{255, 255, 50, 255})
+			if s.capturing {
+				text = "press something... (ESCAPE to stop)"
+			}
+		} else {
+			label = "  " + label
+			res.Text.SetColor(color.NRGBA{255, 255, 255, 255})
+		}
+		y := s.rowsY + (i-s.top)*optionsRowHeight
+		res.Text.Draw(screen, label, x+8, y)
+		res.Text.SetColor(color.NRGBA{194, 193, 174, 255})
+		res.Text.Draw(screen, text, x+optionsLabelWidth, y)
+	}
+	if s.top+optionsVisibleRows < s.rows() {
+		res.Text.Draw(screen, "  ...", x+8, s.rowsY+optionsVisibleRows*optionsRowHeight)
+	}
+	res.Text.Utils().RestoreState()
+}
@@ -0,0 +1,174 @@
+package states
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/kettek/ebihack23/inputs"
+	"github.com/kettek/ebihack23/res"
+	"github.com/kettek/ebihack23/rooms"
+	"github.com/kettek/ebihack23/settings"
+	"github.com/tinne26/etxt"
+)
+
+const mapNodeWidth = 100
+const mapNodeHeight = 34
+const mapNodeSpacingX = 106
+const mapNodeSpacingY = 48
+
+type mapNode struct {
+	id    string
+	x, y  float32
+	known bool // Visited. Unvisited neighbours are shown, but not named.
+}
+
+// WorldMap is an overlay showing the rooms that have been visited, laid out from the room graph.
+type WorldMap struct {
+	game  *Game
+	graph map[string][]string
+	nodes map[string]*mapNode
+}
+
+func NewWorldMap(g *Game) *WorldMap {
+	return &WorldMap{
+		game: g,
+	}
+}
+
+func (m *WorldMap) Enter() {
+	m.graph = rooms.Graph()
+	m.layout()
+}
+
+func (m *WorldMap) Leave() {
+}
+
+// layout places rooms in columns by their distance from the starting room.
+func (m *WorldMap) layout() {
+	visited := m.game.world.Visited
+	depths := map[string]int{StartRoom: 0}
+	order := []string{StartRoom}
+	for i := 0; i < len(order); i++ {
+		for _, n := range m.graph[order[i]] {
+			if _, ok := depths[n]; !ok {
+				depths[n] = depths[order[i]] + 1
+				order = append(order, n)
+			}
+		}
+	}
+
+	columns := make(map[int][]string)
+	maxDepth := 0
+	for _, id := range order {
+		show := visited[id]
+		if !show {
+			// Show unvisited rooms next to visited ones, so the player knows there is more.
+			for _, n := range m.graph[id] {
+				if visited[n] {
+					show = true
+					break
+				}
+			}
+		}
+		if !show {
+			continue
+		}
+		d := depths[id]
+		columns[d] = append(columns[d], id)
+		if d > maxDepth {
+			maxDepth = d
+		}
+	}
+
+	m.nodes = make(map[string]*mapNode)
+	w, h := float32(640), float32(480)
+	left := w/2 - float32(maxDepth)*mapNodeSpacingX/2
+	for d := 0; d <= maxDepth; d++ {
+		ids := columns[d]
+		sort.Strings(ids)
+		top := h/2 - float32(len(ids)-1)*mapNodeSpacingY/2
+		for i, id := range ids {
+			m.nodes[id] = &mapNode{
+				id:    id,
+				x:     left + float32(d)*mapNodeSpacingX,
+				y:     top + float32(i)*mapNodeSpacingY,
+				known: visited[id],
+			}
+		}
+	}
+}
+
+func (m *WorldMap) Update() error {
+	res.UpdateSounds()
+	res.Jukebox.Update()
+	if inputs.Actions.JustReleased(settings.ActionMap) || inputs.Actions.JustReleased(settings.ActionCancel) || inputs.Actions.JustReleased(settings.ActionAltClick) {
+		NextState(m.game)
+	}
+	return nil
+}
+
+func (m *WorldMap) Draw(screen *ebiten.Image) {
+	m.game.Draw(screen)
+
+	vector.DrawFilledRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()), color.NRGBA{0, 0, 0, 200}, false)
+
+	// Links first so they're under the nodes.
+	lineColor := color.NRGBA{194, 193, 174, 200}
+	for id, n := range m.nodes {
+		for _, other := range m.graph[id] {
+			if o, ok := m.nodes[other]; ok && id < other {
+				vector.StrokeLine(screen, n.x, n.y, o.x, o.y, 2, lineColor, true)
+			}
+		}
+	}
+
+	res.Text.Utils().StoreState()
+	res.Text.SetFont(res.SmallFont.Font)
+	res.Text.SetSize(float64(res.SmallFont.Size))
+	res.Text.SetAlign(etxt.Center)
+
+	current := m.game.world.Room
+	for id, n := range m.nodes {
+		x := n.x - mapNodeWidth/2
+		y := n.y - mapNodeHeight/2
+		bg := color.NRGBA{19, 19, 97, 230}
+		border := color.NRGBA{194, 193, 174, 255}
+		if current != nil && current.ID == id {
+			border = color.NRGBA{255, 255, 50, 255}
+		}
+		vector.DrawFilledRect(screen, x, y, mapNodeWidth, mapNodeHeight, bg, false)
+		vector.StrokeRect(screen, x, y, mapNodeWidth, mapNodeHeight, 3, border, true)
+
+		if !n.known {
+			res.Text.SetColor(color.NRGBA{128, 128, 128, 255})
+			res.Text.Draw(screen, "???", int(n.x), int(n.y))
+			continue
+		}
+		// Visited rooms have been built, so there's no need to build any here.
+		room, ok := rooms.CachedRoom(id)
+		if !ok {
+			continue
+		}
+		res.Text.SetColor(color.NRGBA{255, 255, 255, 255})
+		res.Text.Draw(screen, room.Name, int(n.x), int(n.y)-6)
+		if room.MaxGlitches == 0 {
+			res.Text.SetColor(color.NRGBA{50, 200, 255, 255})
+			res.Text.Draw(screen, "safe", int(n.x), int(n.y)+6)
+		} else if room.Glitches <= 0 {
+			res.Text.SetColor(color.NRGBA{50, 255, 50, 255})
+			res.Text.Draw(screen, "cleansed", int(n.x), int(n.y)+6)
+		} else {
+			res.Text.SetColor(color.NRGBA{255, 50, 255, 255})
+			res.Text.Draw(screen, fmt.Sprintf("%d/%d remain", room.Glitches, room.MaxGlitches), int(n.x), int(n.y)+6)
+		}
+	}
+
+	res.Text.SetFont(res.DefFont.Font)
+	res.Text.SetSize(float64(res.DefFont.Size))
+	res.Text.SetColor(color.NRGBA{245, 245, 220, 255})
+	res.Text.Draw(screen, "WORLD MAP", screen.Bounds().Dx()/2, 20)
+	res.Text.Utils().RestoreState()
+}